@@ -0,0 +1,257 @@
+// Package accesslog parses Aliyun OSS access log records.
+//
+// Format reference: https://help.aliyun.com/document_detail/31868.html
+package accesslog
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeFormat is the format of the time field, e.g. [02/May/2012:00:00:04 +0800].
+const timeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// minFields is the number of fields every record has. Newer log versions
+// append more fields, which are ignored.
+const minFields = 22
+
+// OperationPostObject is the operation of records written for POST uploads.
+const OperationPostObject = "PostObject"
+
+// Record is a single access log entry. Fields logged as "-" are left empty.
+// Key is the decoded object name, RawKey the URL encoded one as logged.
+type Record struct {
+	RemoteIP           string
+	Time               time.Time
+	Method             string
+	RequestURI         string
+	Proto              string
+	HTTPStatus         int
+	SentBytes          int64
+	RequestTime        time.Duration
+	Referer            string
+	UserAgent          string
+	Hostname           string
+	RequestID          string
+	LoggingFlag        bool
+	RequesterID        string
+	Operation          string
+	Bucket             string
+	Key                string
+	RawKey             string
+	ObjectSize         int64
+	ServerCostTime     time.Duration
+	ErrorCode          string
+	RequestLength      int64
+	UserID             string
+	DeltaDataSize      int64
+	SyncRequest        string
+	StorageClass       string
+	TargetStorageClass string
+	AccelerationPoint  string
+	AccessKeyID        string
+}
+
+// IsPostUpload reports whether the record is a POST (form) upload.
+func (r *Record) IsPostUpload() bool {
+	return r.Operation == OperationPostObject
+}
+
+// ParseLine parses a single access log line.
+func ParseLine(line string) (*Record, error) {
+	fields, err := splitFields(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) < minFields {
+		return nil, fmt.Errorf("accesslog: expected at least %d fields, got %d", minFields, len(fields))
+	}
+
+	p := parser{fields: fields}
+	r := &Record{}
+	r.RemoteIP = p.string(0)
+	// Fields 1 and 2 are reserved.
+	r.Time = p.time(3)
+	r.Method, r.RequestURI, r.Proto = p.request(4)
+	r.HTTPStatus = int(p.int(5))
+	r.SentBytes = p.int(6)
+	r.RequestTime = p.millis(7)
+	r.Referer = p.string(8)
+	r.UserAgent = p.string(9)
+	r.Hostname = p.string(10)
+	r.RequestID = p.string(11)
+	r.LoggingFlag = p.string(12) == "true"
+	r.RequesterID = p.string(13)
+	r.Operation = p.string(14)
+	r.Bucket = p.string(15)
+	r.RawKey = p.string(16)
+	r.Key = p.unescape(16)
+	r.ObjectSize = p.int(17)
+	r.ServerCostTime = p.millis(18)
+	r.ErrorCode = p.string(19)
+	r.RequestLength = p.int(20)
+	r.UserID = p.string(21)
+	r.DeltaDataSize = p.int(22)
+	r.SyncRequest = p.string(23)
+	r.StorageClass = p.string(24)
+	r.TargetStorageClass = p.string(25)
+	r.AccelerationPoint = p.string(26)
+	r.AccessKeyID = p.string(27)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return r, nil
+}
+
+// Reader reads records from an access log object.
+type Reader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+// NewReader returns a Reader reading from r.
+func NewReader(r io.Reader) *Reader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return &Reader{s: s}
+}
+
+// Read returns the next record, or io.EOF when there are no more records.
+// Blank lines are skipped.
+func (r *Reader) Read() (*Record, error) {
+	for r.s.Scan() {
+		r.line++
+		line := r.s.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		rec, err := ParseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%v (line %d)", err, r.line)
+		}
+		return rec, nil
+	}
+	if err := r.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// ReadAll reads all remaining records.
+func (r *Reader) ReadAll() ([]*Record, error) {
+	var records []*Record
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, rec)
+	}
+}
+
+// splitFields splits a log line into space separated fields. Quoted
+// ("...") and bracketed ([...]) fields may contain spaces and are returned
+// without their delimiters.
+func splitFields(line string) ([]string, error) {
+	var fields []string
+	for i := 0; i < len(line); {
+		switch line[i] {
+		case ' ':
+			i++
+			continue
+		case '"', '[':
+			end := byte('"')
+			if line[i] == '[' {
+				end = ']'
+			}
+			j := strings.IndexByte(line[i+1:], end)
+			if j < 0 {
+				return nil, errors.New("accesslog: unterminated field")
+			}
+			fields = append(fields, line[i+1:i+1+j])
+			i += j + 2
+		default:
+			j := strings.IndexByte(line[i:], ' ')
+			if j < 0 {
+				j = len(line) - i
+			}
+			fields = append(fields, line[i:i+j])
+			i += j
+		}
+	}
+	return fields, nil
+}
+
+// parser converts fields, remembering the first error.
+type parser struct {
+	fields []string
+	err    error
+}
+
+func (p *parser) string(i int) string {
+	if i >= len(p.fields) || p.fields[i] == "-" {
+		return ""
+	}
+	return p.fields[i]
+}
+
+func (p *parser) int(i int) int64 {
+	s := p.string(i)
+	if s == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("accesslog: invalid number in field %d: %q", i, s)
+	}
+	return n
+}
+
+func (p *parser) unescape(i int) string {
+	s := p.string(i)
+	u, err := url.QueryUnescape(s)
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("accesslog: invalid escape in field %d: %q", i, s)
+	}
+	return u
+}
+
+func (p *parser) millis(i int) time.Duration {
+	return time.Duration(p.int(i)) * time.Millisecond
+}
+
+func (p *parser) time(i int) time.Time {
+	s := p.string(i)
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(timeFormat, s)
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("accesslog: invalid time in field %d: %q", i, s)
+	}
+	return t
+}
+
+func (p *parser) request(i int) (method, uri, proto string) {
+	parts := strings.SplitN(p.string(i), " ", 3)
+	switch len(parts) {
+	case 3:
+		proto = parts[2]
+		fallthrough
+	case 2:
+		uri = parts[1]
+		method = parts[0]
+	default:
+		uri = parts[0]
+	}
+	return method, uri, proto
+}
@@ -0,0 +1,77 @@
+package accesslog
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	getLine  = `192.168.0.1 - - [02/May/2012:00:00:04 +0800] "GET /aliyun-logo.png HTTP/1.1" 200 5576 71 "-" "aliyun-sdk-http/2.6.0 (Linux; x86_64)" "oss-example.oss-cn-hangzhou.aliyuncs.com" "5FF16B65F05BC932307A3C3C" "true" "16571836914537****" "GetObject" "oss-example" "aliyun-logo.png" 5576 10 "-" 272 "16571836914537****" - "-" "standard" "-" "-" "LTAI4FrfJPUSoKm4JHb5****"`
+	postLine = `10.0.0.2 - - [23/Jan/2017:12:05:06 +0800] "POST / HTTP/1.1" 204 0 120 "https://example.com/upload" "Mozilla/5.0" "oss-example.oss-cn-hangzhou.aliyuncs.com" "5885815A08A7F1A7A1B8D4D1" "true" "-" "PostObject" "oss-example" "user%2F1%2Favatar.png" 12345 80 "-" 13000 "16571836914537****" 12345`
+)
+
+func TestParseLine(t *testing.T) {
+	r, err := ParseLine(getLine)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "192.168.0.1", r.RemoteIP)
+	assert.True(t, r.Time.Equal(time.Date(2012, 5, 1, 16, 0, 4, 0, time.UTC)))
+	assert.Equal(t, "GET", r.Method)
+	assert.Equal(t, "/aliyun-logo.png", r.RequestURI)
+	assert.Equal(t, "HTTP/1.1", r.Proto)
+	assert.Equal(t, 200, r.HTTPStatus)
+	assert.Equal(t, int64(5576), r.SentBytes)
+	assert.Equal(t, 71*time.Millisecond, r.RequestTime)
+	assert.Equal(t, "", r.Referer)
+	assert.Equal(t, "aliyun-sdk-http/2.6.0 (Linux; x86_64)", r.UserAgent)
+	assert.Equal(t, "5FF16B65F05BC932307A3C3C", r.RequestID)
+	assert.True(t, r.LoggingFlag)
+	assert.Equal(t, "GetObject", r.Operation)
+	assert.Equal(t, "oss-example", r.Bucket)
+	assert.Equal(t, "aliyun-logo.png", r.Key)
+	assert.Equal(t, int64(5576), r.ObjectSize)
+	assert.Equal(t, 10*time.Millisecond, r.ServerCostTime)
+	assert.Equal(t, int64(272), r.RequestLength)
+	assert.Equal(t, int64(0), r.DeltaDataSize)
+	assert.Equal(t, "standard", r.StorageClass)
+	assert.Equal(t, "LTAI4FrfJPUSoKm4JHb5****", r.AccessKeyID)
+	assert.False(t, r.IsPostUpload())
+}
+
+func TestParseLineInvalid(t *testing.T) {
+	for _, line := range []string{
+		`192.168.0.1 - - [02/May/2012:00:00:04 +0800`,
+		`192.168.0.1 - - [02/May/2012:00:00:04 +0800] "GET / HTTP/1.1" 200`,
+		strings.Replace(getLine, " 5576 71 ", " x 71 ", 1),
+		strings.Replace(getLine, "02/May/2012", "02/05/2012", 1),
+		strings.Replace(getLine, `"aliyun-logo.png"`, `"aliyun-logo%zz.png"`, 1),
+	} {
+		_, err := ParseLine(line)
+		assert.Error(t, err, line)
+	}
+}
+
+func TestReader(t *testing.T) {
+	r := NewReader(strings.NewReader(getLine + "\n\n" + postLine + "\n"))
+	records, err := r.ReadAll()
+	if !assert.NoError(t, err) || !assert.Len(t, records, 2) {
+		return
+	}
+	post := records[1]
+	assert.True(t, post.IsPostUpload())
+	assert.Equal(t, "user/1/avatar.png", post.Key)
+	assert.Equal(t, "user%2F1%2Favatar.png", post.RawKey)
+	assert.Equal(t, int64(12345), post.DeltaDataSize)
+	assert.Equal(t, "", post.StorageClass)
+
+	_, err = r.Read()
+	assert.Equal(t, io.EOF, err)
+
+	_, err = NewReader(strings.NewReader(getLine + "\ngarbage\n")).ReadAll()
+	assert.EqualError(t, err, "accesslog: expected at least 22 fields, got 1 (line 2)")
+}
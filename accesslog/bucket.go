@@ -0,0 +1,45 @@
+package accesslog
+
+import (
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// ListLogObjects returns the keys of the access log objects stored in b
+// under prefix, which is the target prefix configured for bucket logging,
+// optionally followed by the source bucket name and a date, e.g.
+// "log/mybucket2017-01-23".
+func ListLogObjects(b *oss.Bucket, prefix string) ([]string, error) {
+	var keys []string
+	marker := ""
+	for {
+		lor, err := b.ListObjects(oss.Prefix(prefix), oss.Marker(marker))
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range lor.Objects {
+			keys = append(keys, o.Key)
+		}
+		if !lor.IsTruncated {
+			return keys, nil
+		}
+		marker = lor.NextMarker
+	}
+}
+
+// ReadLogObject downloads the access log object key from b and parses it.
+func ReadLogObject(b *oss.Bucket, key string) ([]*Record, error) {
+	body, err := b.GetObject(key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return NewReader(body).ReadAll()
+}
+
+// PresignedLogURL returns a presigned GET url of the access log object key,
+// valid for expires, so it can be downloaded by another process.
+func PresignedLogURL(b *oss.Bucket, key string, expires time.Duration) (string, error) {
+	return b.SignURL(key, oss.HTTPGet, int64(expires/time.Second))
+}
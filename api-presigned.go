@@ -3,40 +3,125 @@ package oss_addons
 import (
 	"errors"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/aliyun/aliyun-oss-go-sdk/oss"
 	"github.com/timonwong/ali-oss-addons/signer"
 )
 
+// timeNow is overridden in tests.
+var timeNow = time.Now
+
+// v4PolicyFields - The form fields PresignedPostPolicyV4 adds to the policy.
+var v4PolicyFields = []string{"x-oss-signature-version", "x-oss-credential", "x-oss-date"}
+
 // PresignedPostPolicyV1 returns POST urlString, form data to upload an object.
 func PresignedPostPolicyV1(c *oss.Client, p *PostPolicy) (u *url.URL, formData map[string]string, err error) {
+	u, err = postPolicyURL(c, p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := p.validateSize(); err != nil {
+		return nil, nil, err
+	}
+
+	// Work on a copy so the policy can be signed again.
+	formData = copyFormData(p.formData)
+	policyBase64 := p.base64()
+	formData["policy"] = policyBase64
+	formData["OSSAccessKeyId"] = c.Config.AccessKeyID
+	// Sign the policy.
+	formData["signature"] = signer.PostPresignSignatureV1(policyBase64, c.Config.AccessKeySecret)
+	return u, formData, nil
+}
+
+// PresignedPostPolicyV4 returns POST urlString, form data to upload an object,
+// signed with the V4 (HMAC-SHA256) signature scheme for the given region,
+// e.g. "cn-hangzhou". The policy must not have conditions on the V4 fields,
+// which are added for the signing time.
+func PresignedPostPolicyV4(c *oss.Client, p *PostPolicy, region string) (u *url.URL, formData map[string]string, err error) {
+	if strings.TrimSpace(region) == "" {
+		return nil, nil, errors.New("region must be specified")
+	}
+	for _, field := range v4PolicyFields {
+		if p.hasCondition(field) {
+			return nil, nil, NewInvalidArgumentErrorf("p", "policy already has a condition on %s", field)
+		}
+	}
+	u, err = postPolicyURL(c, p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	t := timeNow().UTC()
+	credential := signer.CredentialV4(c.Config.AccessKeyID, t, region)
+	date := t.Format(signer.DateFormatV4)
+
+	// The V4 fields must be covered by the policy too, add them to a copy
+	// so the policy can be signed again.
+	p = p.clone()
+	for i, value := range []string{signer.SignatureVersionV4, credential, date} {
+		field := v4PolicyFields[i]
+		policyCond := Condition{
+			matchType: "eq",
+			condition: "$" + field,
+			value:     value,
+		}
+		if err := p.addNewPolicy("p", policyCond); err != nil {
+			return nil, nil, err
+		}
+		p.formData[field] = value
+	}
+	if err := p.validateSize(); err != nil {
+		return nil, nil, err
+	}
+
+	formData = p.formData
+	policyBase64 := p.base64()
+	formData["policy"] = policyBase64
+	// Sign the policy.
+	formData["x-oss-signature"] = signer.PostPresignSignatureV4(policyBase64, c.Config.AccessKeySecret, t, region)
+	return u, formData, nil
+}
+
+// postPolicyURL validates the policy and builds the target url.
+func postPolicyURL(c *oss.Client, p *PostPolicy) (*url.URL, error) {
 	// Validate input arguments.
 	if p.expiration.IsZero() {
-		return nil, nil, errors.New("expiration time must be specified")
+		return nil, errors.New("expiration time must be specified")
 	}
 	if _, ok := p.formData["key"]; !ok {
-		return nil, nil, errors.New("object key must be specified")
+		return nil, errors.New("object key must be specified")
 	}
 	if _, ok := p.formData["bucket"]; !ok {
-		return nil, nil, errors.New("bucket name must be specified")
+		return nil, errors.New("bucket name must be specified")
+	}
+
+	if err := p.validateStrict(); err != nil {
+		return nil, err
 	}
 
 	bucketName := p.formData["bucket"]
 
 	// Build target url
-	u, err = url.Parse(c.Config.Endpoint)
+	u, err := url.Parse(c.Config.Endpoint)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if !c.Config.IsCname {
 		u.Path = "/" + bucketName
 	}
+	return u, nil
+}
 
-	policyBase64 := p.base64()
-	p.formData["policy"] = policyBase64
-	p.formData["OSSAccessKeyId"] = c.Config.AccessKeyID
-	// Sign the policy.
-	p.formData["signature"] = signer.PostPresignSignatureV1(policyBase64, c.Config.AccessKeySecret)
-	return u, p.formData, nil
+// copyFormData - Returns a shallow copy of form data.
+func copyFormData(formData map[string]string) map[string]string {
+	m := make(map[string]string, len(formData))
+	for k, v := range formData {
+		m[k] = v
+	}
+	return m
 }
@@ -0,0 +1,152 @@
+package oss_addons
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/stretchr/testify/assert"
+	"github.com/timonwong/ali-oss-addons/osstest"
+)
+
+// newTestClient returns a client with fixed test credentials for signing
+// without a bucket.
+func newTestClient() *oss.Client {
+	return &oss.Client{Config: &oss.Config{
+		Endpoint:        "https://oss-cn-hangzhou.aliyuncs.com",
+		AccessKeyID:     "test-access-key-id",
+		AccessKeySecret: "test-access-key-secret",
+	}}
+}
+
+func TestPresignedPostPolicyV1Integration(t *testing.T) {
+	bucket := osstest.RequireRealBucket(t)
+	key := osstest.ObjectKey(t)
+	defer osstest.DeleteObject(t, bucket, key)
+
+	content := []byte("hello from ali-oss-addons")
+
+	policy := NewPostPolicy()
+	policy.SetExpires(time.Now().UTC().Add(10 * time.Minute))
+	policy.SetBucket(bucket.BucketName)
+	policy.SetKey(key)
+	policy.SetContentLengthRange(1, int64(len(content)))
+
+	u, formData, err := PresignedPostPolicyV1(&bucket.Client, policy)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for k, v := range formData {
+		w.WriteField(k, v)
+	}
+	// The file field must be the last one in the form.
+	fw, err := w.CreateFormFile("file", "hello.txt")
+	if !assert.NoError(t, err) {
+		return
+	}
+	fw.Write(content)
+	w.Close()
+
+	resp, err := http.Post(u.String(), w.FormDataContentType(), &body)
+	if !assert.NoError(t, err) {
+		return
+	}
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !assert.Equal(t, http.StatusNoContent, resp.StatusCode, "unexpected response: %s", respBody) {
+		return
+	}
+
+	r, err := bucket.GetObject(key)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer r.Close()
+	got, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, content, got)
+}
+
+func TestPresignedPostPolicyV1DoesNotMutatePolicy(t *testing.T) {
+	c := newTestClient()
+
+	policy := NewPostPolicy()
+	policy.SetExpires(time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC))
+	policy.SetBucket("test-bucket")
+	policy.SetKey("test-object-name")
+	want := copyFormData(policy.formData)
+
+	_, first, err := PresignedPostPolicyV1(c, policy)
+	if !assert.NoError(t, err) {
+		return
+	}
+	first["signature"] = "tampered"
+
+	_, second, err := PresignedPostPolicyV1(c, policy)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, want, policy.formData, "Signing should not modify the policy's form data")
+	assert.NotEqual(t, "tampered", second["signature"])
+	assert.Contains(t, second, "policy")
+	assert.Contains(t, second, "OSSAccessKeyId")
+}
+
+func TestPresignedPostPolicyV4(t *testing.T) {
+	now := time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	c := newTestClient()
+
+	policy := NewPostPolicy()
+	policy.SetExpires(now.Add(time.Hour))
+	policy.SetBucket("test-bucket")
+	policy.SetKey("test-object-name")
+	want := copyFormData(policy.formData)
+
+	_, formData, err := PresignedPostPolicyV4(c, policy, "cn-hangzhou")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, want, policy.formData, "Signing should not modify the policy's form data")
+	assert.Len(t, policy.conditions, 2, "Signing should not modify the policy's conditions")
+
+	credential := "test-access-key-id/20170123/cn-hangzhou/oss/aliyun_v4_request"
+	assert.Equal(t, "OSS4-HMAC-SHA256", formData["x-oss-signature-version"])
+	assert.Equal(t, credential, formData["x-oss-credential"])
+	assert.Equal(t, "20170123T040506Z", formData["x-oss-date"])
+	assert.NotContains(t, formData, "signature")
+	assert.NotContains(t, formData, "OSSAccessKeyId")
+
+	// The signature was computed with an independent HMAC implementation;
+	// the signer itself is checked against the official SDK vectors in
+	// package testvectors.
+	policyJSON, err := base64.StdEncoding.DecodeString(formData["policy"])
+	if assert.NoError(t, err) {
+		assert.Equal(t,
+			`{"expiration":"2017-01-23T05:05:06Z","conditions":[["eq","$bucket","test-bucket"],["eq","$key","test-object-name"],`+
+				`["eq","$x-oss-signature-version","OSS4-HMAC-SHA256"],["eq","$x-oss-credential","`+credential+`"],["eq","$x-oss-date","20170123T040506Z"]]}`,
+			string(policyJSON))
+	}
+	assert.Equal(t, "3718ec06fa09dd31a2f12aba26c1d3c243cfab198c99ba2424ab2f2e5a5a38da", formData["x-oss-signature"])
+
+	_, _, err = PresignedPostPolicyV4(c, policy, "")
+	assert.Error(t, err)
+
+	// A policy signed before carries stale V4 fields, which would
+	// contradict the new ones.
+	signed, err := ParsePostPolicy(policyJSON)
+	if assert.NoError(t, err) {
+		_, _, err = PresignedPostPolicyV4(c, signed, "cn-hangzhou")
+		assert.EqualError(t, err, "policy already has a condition on x-oss-signature-version")
+	}
+}
@@ -0,0 +1,57 @@
+package callback
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/url"
+)
+
+// ParseBody parses a callback body into its variables. Bodies are
+// form-urlencoded unless contentType is application/json, following the
+// callbackBodyType of the callback parameter. Non-string JSON values are
+// returned as their JSON text.
+func ParseBody(contentType string, body []byte) (map[string]string, error) {
+	if len(body) > DefaultMaxBodySize {
+		return nil, ErrBodyTooLarge
+	}
+
+	mediaType := "application/x-www-form-urlencoded"
+	if contentType != "" {
+		var err error
+		if mediaType, _, err = mime.ParseMediaType(contentType); err != nil {
+			return nil, err
+		}
+	}
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, err
+		}
+		vars := make(map[string]string, len(values))
+		for k, v := range values {
+			vars[k] = v[0]
+		}
+		return vars, nil
+	case "application/json":
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(body, &raw); err != nil {
+			return nil, err
+		}
+		vars := make(map[string]string, len(raw))
+		for k, v := range raw {
+			var s string
+			if err := json.Unmarshal(v, &s); err == nil {
+				vars[k] = s
+			} else {
+				vars[k] = string(bytes.TrimSpace(v))
+			}
+		}
+		return vars, nil
+	default:
+		return nil, errors.New("callback: unsupported body type " + mediaType)
+	}
+}
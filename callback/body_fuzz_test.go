@@ -0,0 +1,18 @@
+//go:build go1.18
+// +build go1.18
+
+package callback
+
+import (
+	"testing"
+)
+
+func FuzzParseBody(f *testing.F) {
+	f.Add("", []byte("bucket=test-bucket&object=a%2Fb.png&size=42&mimeType=image%2Fpng"))
+	f.Add("application/json", []byte(`{"bucket":"test-bucket","object":"a.png","size":42,"height":null}`))
+	f.Add("application/json; charset=utf-8", []byte(`{"x:uid":"é"}`))
+	f.Add("application/x-www-form-urlencoded", []byte("a=1&a=2&b"))
+	f.Fuzz(func(t *testing.T, contentType string, body []byte) {
+		ParseBody(contentType, body)
+	})
+}
@@ -0,0 +1,35 @@
+package callback
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBody(t *testing.T) {
+	vars, err := ParseBody("", []byte("bucket=test-bucket&object=a%2Fb.png&size=42&x%3Auid=7"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]string{
+			"bucket": "test-bucket",
+			"object": "a/b.png",
+			"size":   "42",
+			"x:uid":  "7",
+		}, vars)
+	}
+
+	vars, err = ParseBody("application/json; charset=utf-8", []byte(`{"bucket":"test-bucket","size":42,"x:uid":"7"}`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]string{
+			"bucket": "test-bucket",
+			"size":   "42",
+			"x:uid":  "7",
+		}, vars)
+	}
+
+	_, err = ParseBody("application/json", []byte(`["bucket"]`))
+	assert.Error(t, err)
+	_, err = ParseBody("text/plain", []byte("bucket"))
+	assert.Error(t, err)
+	_, err = ParseBody("application/x-www-form-urlencoded", []byte("bucket=%zz"))
+	assert.Error(t, err)
+}
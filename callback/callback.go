@@ -0,0 +1,171 @@
+// Package callback verifies the requests Aliyun OSS sends to callback urls
+// after uploads.
+//
+// OSS signs every callback request: the x-oss-pub-key-url header carries the
+// base64 encoded url of an RSA public key, and the Authorization header the
+// base64 encoded MD5-with-RSA signature of
+//
+//	url_decode(path) + query + "\n" + body
+//
+// See https://help.aliyun.com/document_detail/31989.html
+package callback
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/md5"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultPublicKeyURLPrefixes are the url prefixes OSS publishes its
+// callback public keys under.
+var DefaultPublicKeyURLPrefixes = []string{
+	"http://gosspublic.alicdn.com/",
+	"https://gosspublic.alicdn.com/",
+}
+
+// publicKeyHTTPPrefix is the plain http prefix OSS sends, which
+// publicKeyURL upgrades to https.
+const publicKeyHTTPPrefix = "http://gosspublic.alicdn.com/"
+
+// DefaultMaxBodySize is the default limit of callback bodies.
+const DefaultMaxBodySize = 1 << 20
+
+// Verification errors.
+var (
+	ErrMissingPublicKeyURL   = errors.New("callback: missing x-oss-pub-key-url header")
+	ErrUntrustedPublicKeyURL = errors.New("callback: untrusted public key url")
+	ErrMissingAuthorization  = errors.New("callback: missing authorization header")
+	ErrSignatureMismatch     = errors.New("callback: signature mismatch")
+	ErrBodyTooLarge          = errors.New("callback: body too large")
+)
+
+// Verifier verifies OSS callback requests.
+type Verifier struct {
+	// Fetcher fetches public keys, NewVerifier uses a cached HTTP fetcher.
+	Fetcher PublicKeyFetcher
+	// PublicKeyURLPrefixes lists the trusted public key url prefixes.
+	PublicKeyURLPrefixes []string
+	// MaxBodySize limits the size of request bodies.
+	MaxBodySize int64
+}
+
+// NewVerifier returns a Verifier trusting DefaultPublicKeyURLPrefixes, which
+// fetches public keys over https with DefaultFetchTimeout and caches them.
+func NewVerifier() *Verifier {
+	return &Verifier{
+		Fetcher:              NewCachedPublicKeyFetcher(&HTTPPublicKeyFetcher{}),
+		PublicKeyURLPrefixes: DefaultPublicKeyURLPrefixes,
+		MaxBodySize:          DefaultMaxBodySize,
+	}
+}
+
+// VerifyRequest verifies the signature of r. The request body is consumed
+// and replaced, so it can still be read by the caller.
+func (v *Verifier) VerifyRequest(r *http.Request) error {
+	pubKeyURL, err := v.publicKeyURL(r)
+	if err != nil {
+		return err
+	}
+	signature, err := base64.StdEncoding.DecodeString(r.Header.Get("Authorization"))
+	if err != nil || len(signature) == 0 {
+		return ErrMissingAuthorization
+	}
+
+	body, err := v.readBody(r)
+	if err != nil {
+		return err
+	}
+
+	pubKey, err := v.Fetcher.FetchPublicKey(pubKeyURL)
+	if err != nil {
+		return err
+	}
+
+	digest := md5.Sum(stringToSign(r, body))
+	if err := rsa.VerifyPKCS1v15(pubKey, crypto.MD5, digest[:], signature); err != nil {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// Handler returns a middleware rejecting requests failing VerifyRequest with
+// 403 Forbidden.
+func (v *Verifier) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := v.VerifyRequest(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// publicKeyURL decodes and validates the x-oss-pub-key-url header. OSS
+// sends http urls, which are fetched over https instead so the key cannot
+// be replaced in transit.
+func (v *Verifier) publicKeyURL(r *http.Request) (string, error) {
+	header := r.Header.Get("x-oss-pub-key-url")
+	if header == "" {
+		return "", ErrMissingPublicKeyURL
+	}
+	decoded, err := base64.StdEncoding.DecodeString(header)
+	if err != nil {
+		return "", ErrUntrustedPublicKeyURL
+	}
+	pubKeyURL := string(decoded)
+	for _, prefix := range v.PublicKeyURLPrefixes {
+		if strings.HasPrefix(pubKeyURL, prefix) {
+			if strings.HasPrefix(pubKeyURL, publicKeyHTTPPrefix) {
+				pubKeyURL = "https://" + strings.TrimPrefix(pubKeyURL, "http://")
+			}
+			return pubKeyURL, nil
+		}
+	}
+	return "", ErrUntrustedPublicKeyURL
+}
+
+// readBody reads the request body and replaces it with an in-memory copy.
+func (v *Verifier) readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	maxBodySize := v.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultMaxBodySize
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > maxBodySize {
+		return nil, ErrBodyTooLarge
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// stringToSign builds url_decode(path) + query + "\n" + body.
+func stringToSign(r *http.Request, body []byte) []byte {
+	path, err := url.PathUnescape(r.URL.EscapedPath())
+	if err != nil {
+		path = r.URL.Path
+	}
+	buf := make([]byte, 0, len(path)+len(r.URL.RawQuery)+len(body)+2)
+	buf = append(buf, path...)
+	if r.URL.RawQuery != "" {
+		buf = append(buf, '?')
+		buf = append(buf, r.URL.RawQuery...)
+	}
+	buf = append(buf, '\n')
+	buf = append(buf, body...)
+	return buf
+}
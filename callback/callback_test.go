@@ -0,0 +1,179 @@
+package callback
+
+import (
+	"crypto"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPublicKeyURL = "https://gosspublic.alicdn.com/callback_pub_key_v1.pem"
+
+type countingFetcher struct {
+	key   *rsa.PublicKey
+	calls int
+	url   string
+}
+
+func (f *countingFetcher) FetchPublicKey(url string) (*rsa.PublicKey, error) {
+	f.calls++
+	f.url = url
+	return f.key, nil
+}
+
+func newSignedRequest(t *testing.T, key *rsa.PrivateKey, target, body string) *http.Request {
+	r := httptest.NewRequest("POST", target, strings.NewReader(body))
+	digest := md5.Sum(stringToSign(r, []byte(body)))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.MD5, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("x-oss-pub-key-url", base64.StdEncoding.EncodeToString([]byte(testPublicKeyURL)))
+	r.Header.Set("Authorization", base64.StdEncoding.EncodeToString(signature))
+	return r
+}
+
+func TestVerifyRequest(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetcher := &countingFetcher{key: &key.PublicKey}
+	v := NewVerifier()
+	v.Fetcher = NewCachedPublicKeyFetcher(fetcher)
+
+	body := "bucket=test-bucket&object=a%2Fb.png&size=42"
+	r := newSignedRequest(t, key, "/callback/upload%20done?tenant=1", body)
+	if assert.NoError(t, v.VerifyRequest(r)) {
+		got, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, body, string(got), "The body should be readable after verification")
+	}
+
+	r = newSignedRequest(t, key, "/callback", body)
+	assert.NoError(t, v.VerifyRequest(r))
+	assert.Equal(t, 1, fetcher.calls, "The public key should be cached")
+
+	r = newSignedRequest(t, key, "/callback", body)
+	r.Body = ioutil.NopCloser(strings.NewReader(body + "&size=1"))
+	assert.Equal(t, ErrSignatureMismatch, v.VerifyRequest(r))
+
+	r = newSignedRequest(t, key, "/callback", body)
+	r.URL.RawQuery = "tenant=2"
+	assert.Equal(t, ErrSignatureMismatch, v.VerifyRequest(r))
+
+	r = newSignedRequest(t, key, "/callback", body)
+	r.Header.Set("x-oss-pub-key-url", base64.StdEncoding.EncodeToString([]byte("http://gosspublic.alicdn.com/callback_pub_key_v2.pem")))
+	assert.NoError(t, v.VerifyRequest(r))
+	assert.Equal(t, "https://gosspublic.alicdn.com/callback_pub_key_v2.pem", fetcher.url,
+		"The public key should be fetched over https")
+
+	r = newSignedRequest(t, key, "/callback", body)
+	r.Header.Set("x-oss-pub-key-url", base64.StdEncoding.EncodeToString([]byte("https://evil.com/key.pem")))
+	assert.Equal(t, ErrUntrustedPublicKeyURL, v.VerifyRequest(r))
+
+	r = newSignedRequest(t, key, "/callback", body)
+	r.Header.Del("x-oss-pub-key-url")
+	assert.Equal(t, ErrMissingPublicKeyURL, v.VerifyRequest(r))
+
+	r = newSignedRequest(t, key, "/callback", body)
+	r.Header.Del("Authorization")
+	assert.Equal(t, ErrMissingAuthorization, v.VerifyRequest(r))
+
+	v.MaxBodySize = 8
+	r = newSignedRequest(t, key, "/callback", body)
+	assert.Equal(t, ErrBodyTooLarge, v.VerifyRequest(r))
+}
+
+// knownAnswerPublicKey and knownAnswerAuthorization were produced with
+// openssl, independently of stringToSign:
+//
+//	printf '/callback/upload done?tenant=1&x=a%%2Fb\nbucket=test-bucket&object=a%%2Fb.png&size=42' |
+//		openssl dgst -md5 -sign key.pem | base64
+const (
+	knownAnswerPublicKey = `-----BEGIN PUBLIC KEY-----
+MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQC0fwCzb5El1ZjxLReaLkBJInAf
+o8SurC1w7B4nCeJewaqCpyI3JkzCwersQ6WmJnX/nS87aIgfKAJLCxQoc4akKOJ2
+tLuqNSAyOCirEfUJjrWZQ2mDCYXYjJHsXVIwXu2owg+bj8PjKxJJ8GwXj1epJomV
+qPInmwsXS8slmp0i8wIDAQAB
+-----END PUBLIC KEY-----
+`
+	knownAnswerAuthorization = "jn2NruVq1ZIpsGSkWS+G6TlPeSKV9Xr0C/EHvqpsK3NrSb6dgATVd58waRYm2NKZjg18OqiIYuukq9RPAliUOt4vShBCGZZ9KAgApeR4HVx36TqiGHUWfsGVqiuwckrUBOqpmYX62YeL1Cs4VTiD19k1jM22ypRh4XbLGnz6ff0="
+)
+
+func TestVerifyRequestKnownAnswer(t *testing.T) {
+	pubKey, err := ParsePublicKey([]byte(knownAnswerPublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewVerifier()
+	v.Fetcher = &countingFetcher{key: pubKey}
+
+	newRequest := func(target string) *http.Request {
+		r := httptest.NewRequest("POST", target, strings.NewReader("bucket=test-bucket&object=a%2Fb.png&size=42"))
+		r.Header.Set("x-oss-pub-key-url", base64.StdEncoding.EncodeToString([]byte("http://gosspublic.alicdn.com/callback_pub_key_v1.pem")))
+		r.Header.Set("Authorization", knownAnswerAuthorization)
+		return r
+	}
+	assert.NoError(t, v.VerifyRequest(newRequest("/callback/upload%20done?tenant=1&x=a%2Fb")))
+	assert.Equal(t, ErrSignatureMismatch, v.VerifyRequest(newRequest("/callback/upload%20done?tenant=1&x=a/b")),
+		"The query should be signed as sent")
+	assert.Equal(t, ErrSignatureMismatch, v.VerifyRequest(newRequest("/callback/upload%20done")))
+}
+
+func TestHandler(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewVerifier()
+	v.Fetcher = &countingFetcher{key: &key.PublicKey}
+	h := v.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Status":"OK"}`))
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newSignedRequest(t, key, "/callback", "object=a.png"))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/callback", strings.NewReader("object=a.png")))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestHTTPPublicKeyFetcher(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/key.pem" {
+			http.NotFound(w, r)
+			return
+		}
+		pem.Encode(w, &pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	}))
+	defer ts.Close()
+
+	f := &HTTPPublicKeyFetcher{}
+	assert.Equal(t, DefaultFetchTimeout, f.client().Timeout, "The default client should time out")
+	pubKey, err := f.FetchPublicKey(ts.URL + "/key.pem")
+	if assert.NoError(t, err) {
+		assert.Equal(t, key.PublicKey.N, pubKey.N)
+	}
+	_, err = f.FetchPublicKey(ts.URL + "/missing.pem")
+	assert.Error(t, err)
+}
@@ -0,0 +1,113 @@
+package callback
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxPublicKeySize limits the size of fetched public keys.
+const maxPublicKeySize = 16 * 1024
+
+// DefaultFetchTimeout is the timeout of HTTPPublicKeyFetcher requests when
+// no Client is set.
+const DefaultFetchTimeout = 10 * time.Second
+
+var defaultFetchClient = &http.Client{Timeout: DefaultFetchTimeout}
+
+// PublicKeyFetcher fetches the RSA public key published at a url.
+type PublicKeyFetcher interface {
+	FetchPublicKey(url string) (*rsa.PublicKey, error)
+}
+
+// HTTPPublicKeyFetcher fetches PEM encoded public keys over HTTP.
+type HTTPPublicKeyFetcher struct {
+	// Client is used for requests. If nil, a client with DefaultFetchTimeout
+	// is used.
+	Client *http.Client
+}
+
+// FetchPublicKey implements PublicKeyFetcher.
+func (f *HTTPPublicKeyFetcher) FetchPublicKey(url string) (*rsa.PublicKey, error) {
+	resp, err := f.client().Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("callback: unable to fetch public key: %s", resp.Status)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxPublicKeySize))
+	if err != nil {
+		return nil, err
+	}
+	return ParsePublicKey(data)
+}
+
+func (f *HTTPPublicKeyFetcher) client() *http.Client {
+	if f.Client != nil {
+		return f.Client
+	}
+	return defaultFetchClient
+}
+
+// ParsePublicKey parses a PEM encoded PKIX RSA public key.
+func ParsePublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("callback: no PEM data found in public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("callback: public key is not an RSA key")
+	}
+	return pubKey, nil
+}
+
+// CachedPublicKeyFetcher caches the keys fetched by another fetcher.
+// OSS rotates keys by publishing them under new urls, so entries never
+// expire.
+type CachedPublicKeyFetcher struct {
+	fetcher PublicKeyFetcher
+
+	mu   sync.RWMutex
+	keys map[string]*rsa.PublicKey
+}
+
+// NewCachedPublicKeyFetcher returns a fetcher caching keys fetched by fetcher.
+func NewCachedPublicKeyFetcher(fetcher PublicKeyFetcher) *CachedPublicKeyFetcher {
+	return &CachedPublicKeyFetcher{
+		fetcher: fetcher,
+		keys:    make(map[string]*rsa.PublicKey),
+	}
+}
+
+// FetchPublicKey implements PublicKeyFetcher.
+func (f *CachedPublicKeyFetcher) FetchPublicKey(url string) (*rsa.PublicKey, error) {
+	f.mu.RLock()
+	key, ok := f.keys[url]
+	f.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	key, err := f.fetcher.FetchPublicKey(url)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.keys[url] = key
+	f.mu.Unlock()
+	return key, nil
+}
@@ -0,0 +1,48 @@
+package oss_addons
+
+import (
+	"strings"
+)
+
+// WithClientHints - Returns a copy of the policy tailored to the size and
+// content type the client declared for its file: the content length range
+// is narrowed to exactly size and Content-Type conditions are replaced by
+// an exact match. The hints must satisfy the policy's own content length
+// range and Content-Type conditions, otherwise an InvalidArgumentError is
+// returned. The policy itself is not modified.
+func (p *PostPolicy) WithClientHints(size int64, contentType string) (*PostPolicy, error) {
+	if size < 0 {
+		return nil, NewInvalidArgumentErrorf("size", "declared size cannot be negative")
+	}
+	if strings.TrimSpace(contentType) == "" || contentType == "" {
+		return nil, NewInvalidArgumentErrorf("contentType", "no declared content type")
+	}
+	if p.contentLengthRange.set {
+		if size < p.contentLengthRange.min || size > p.contentLengthRange.max {
+			return nil, NewInvalidArgumentErrorf("size", "declared size %d is out of the allowed range [%d, %d]",
+				size, p.contentLengthRange.min, p.contentLengthRange.max)
+		}
+	}
+
+	c := p.clone()
+	c.conditions = c.conditions[:0]
+	for _, po := range p.conditions {
+		if !strings.EqualFold(po.condition, "$Content-Type") {
+			c.conditions = append(c.conditions, po)
+			continue
+		}
+		if po.matchType == "eq" && contentType != po.value ||
+			po.matchType == "starts-with" && !strings.HasPrefix(contentType, po.value) {
+			return nil, NewInvalidArgumentErrorf("contentType", "declared content type %s is not allowed", contentType)
+		}
+	}
+	delete(c.formData, "Content-Type")
+
+	if err := c.SetContentType(contentType); err != nil {
+		return nil, err
+	}
+	if err := c.SetContentLengthRange(size, size); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
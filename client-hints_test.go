@@ -0,0 +1,64 @@
+package oss_addons
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostPolicyWithClientHints(t *testing.T) {
+	policy := NewPostPolicy()
+	policy.SetExpires(time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC))
+	policy.SetContentLengthRange(1, 1024)
+	policy.SetBucket("test-bucket")
+	policy.SetKeyStartsWith("user/1/")
+	policy.AddCondition(StartsWith("Content-Type", "image/"))
+	template := policy.String()
+
+	tailored, err := policy.WithClientHints(512, "image/png")
+	if assert.NoError(t, err) {
+		assert.Equal(t,
+			`{"expiration":"2017-01-23T04:05:06Z","conditions":[["content-length-range",512,512],["eq","$bucket","test-bucket"],["starts-with","$key","user/1/"],["eq","$Content-Type","image/png"]]}`,
+			tailored.String())
+		assert.Equal(t, "image/png", tailored.formData["Content-Type"])
+	}
+	assert.Equal(t, template, policy.String(), "The template policy should not be modified")
+
+	_, err = policy.WithClientHints(2048, "image/png")
+	assert.IsType(t, &InvalidArgumentError{}, err)
+	_, err = policy.WithClientHints(512, "text/html")
+	assert.IsType(t, &InvalidArgumentError{}, err)
+	_, err = policy.WithClientHints(-1, "image/png")
+	assert.IsType(t, &InvalidArgumentError{}, err)
+	_, err = policy.WithClientHints(512, "")
+	assert.IsType(t, &InvalidArgumentError{}, err)
+
+	policy.SetStrict(true)
+	_, err = policy.WithClientHints(512, "image/png")
+	assert.NoError(t, err, "Replacing the Content-Type condition should not count as a duplicate")
+}
+
+func TestPostPolicyWithClientHintsEmptyFile(t *testing.T) {
+	policy := NewPostPolicy()
+	policy.SetExpires(time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC))
+	policy.SetContentLengthRange(0, 1024)
+	policy.SetKeyStartsWith("user/1/")
+
+	tailored, err := policy.WithClientHints(0, "text/plain")
+	if assert.NoError(t, err) {
+		assert.Equal(t,
+			`{"expiration":"2017-01-23T04:05:06Z","conditions":[["content-length-range",0,0],["starts-with","$key","user/1/"],["eq","$Content-Type","text/plain"]]}`,
+			tailored.String(), "A 0-byte range should not be dropped")
+
+		form := map[string]string{"key": "user/1/a.txt", "Content-Type": "text/plain"}
+		now := time.Date(2017, 1, 23, 0, 0, 0, 0, time.UTC)
+		assert.NoError(t, Evaluate(tailored, form, 0, now))
+		assert.Error(t, Evaluate(tailored, form, 1, now))
+
+		parsed, err := ParsePostPolicy([]byte(tailored.String()))
+		if assert.NoError(t, err) {
+			assert.Equal(t, tailored.String(), parsed.String())
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package oss_addons
+
+import (
+	"strings"
+)
+
+// Condition - A POST policy condition, build one with Eq, StartsWith or
+// LengthRange and add it with PostPolicy.AddCondition.
+//
+// Explanation:
+// https://help.aliyun.com/document_detail/31988.html
+// https://yq.aliyun.com/articles/58524
+type Condition struct {
+	matchType string
+	condition string
+	value     string
+	min       int64
+	max       int64
+}
+
+// Eq - Returns a condition requiring form field to equal value, e.g.
+// Eq("Cache-Control", "no-cache"). The leading "$" of field is optional.
+func Eq(field, value string) Condition {
+	return Condition{
+		matchType: "eq",
+		condition: conditionField(field),
+		value:     value,
+	}
+}
+
+// StartsWith - Returns a condition requiring form field to start with
+// prefix, e.g. StartsWith("Content-Type", "image/"). The leading "$" of
+// field is optional.
+func StartsWith(field, prefix string) Condition {
+	return Condition{
+		matchType: "starts-with",
+		condition: conditionField(field),
+		value:     prefix,
+	}
+}
+
+// LengthRange - Returns a condition limiting the size of the uploaded
+// content to [min, max] bytes.
+func LengthRange(min, max int64) Condition {
+	return Condition{
+		matchType: "content-length-range",
+		min:       min,
+		max:       max,
+	}
+}
+
+// MatchType - Returns "eq", "starts-with" or "content-length-range".
+func (c Condition) MatchType() string {
+	return c.matchType
+}
+
+// Field - Returns the form field of the condition, e.g. "$key", or an empty
+// string for length ranges.
+func (c Condition) Field() string {
+	return c.condition
+}
+
+// Value - Returns the expected value or prefix of the condition.
+func (c Condition) Value() string {
+	return c.value
+}
+
+// Range - Returns the bounds of a length range condition.
+func (c Condition) Range() (min, max int64) {
+	return c.min, c.max
+}
+
+// conditionField - Prefixes field with "$" unless it already is.
+func conditionField(field string) string {
+	if strings.HasPrefix(field, "$") {
+		return field
+	}
+	return "$" + field
+}
@@ -0,0 +1,54 @@
+package oss_addons
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCondition(t *testing.T) {
+	c := Eq("Cache-Control", "no-cache")
+	assert.Equal(t, "eq", c.MatchType())
+	assert.Equal(t, "$Cache-Control", c.Field())
+	assert.Equal(t, "no-cache", c.Value())
+
+	c = StartsWith("$key", "user/1/")
+	assert.Equal(t, "starts-with", c.MatchType())
+	assert.Equal(t, "$key", c.Field())
+	assert.Equal(t, "user/1/", c.Value())
+
+	c = LengthRange(1, 1024)
+	assert.Equal(t, "content-length-range", c.MatchType())
+	min, max := c.Range()
+	assert.Equal(t, int64(1), min)
+	assert.Equal(t, int64(1024), max)
+}
+
+func TestPostPolicyAddCondition(t *testing.T) {
+	policy := NewPostPolicy()
+	policy.SetExpires(time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC))
+	assert.NoError(t, policy.AddCondition(LengthRange(1, 1024)))
+	assert.NoError(t, policy.AddCondition(StartsWith("key", "user/1/")))
+	assert.NoError(t, policy.AddCondition(Eq("bucket", "test-bucket")))
+
+	assert.Equal(t,
+		`{"expiration":"2017-01-23T04:05:06Z","conditions":[["content-length-range",1,1024],["starts-with","$key","user/1/"],["eq","$bucket","test-bucket"]]}`,
+		policy.String())
+	assert.Empty(t, policy.formData)
+}
+
+func TestPostPolicyAddConditionEmptyPrefix(t *testing.T) {
+	policy := NewPostPolicy()
+	policy.SetExpires(time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC))
+	assert.NoError(t, policy.AddCondition(StartsWith("key", "")))
+	assert.NoError(t, policy.AddCondition(StartsWith("Content-Type", "")))
+	assert.IsType(t, &InvalidArgumentError{}, policy.AddCondition(Eq("bucket", "")))
+
+	data := `{"expiration":"2017-01-23T04:05:06Z","conditions":[["starts-with","$key",""],["starts-with","$Content-Type",""]]}`
+	assert.Equal(t, data, policy.String())
+	parsed, err := ParsePostPolicy([]byte(data))
+	if assert.NoError(t, err) {
+		assert.Equal(t, policy.String(), parsed.String(), "The builder should produce what the parser accepts")
+	}
+}
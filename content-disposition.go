@@ -0,0 +1,77 @@
+package oss_addons
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ContentDisposition - Builds a Content-Disposition value for filename that
+// works for UTF-8 (e.g. CJK) names: an ASCII fallback in filename, and the
+// full name in RFC 5987 filename*. dispositionType is usually "attachment"
+// or "inline". Directory components of filename are dropped.
+//
+// Use it as the value of PostPolicy.SetContentDisposition, or as the
+// response-content-disposition override of presigned GET urls
+// (oss.ResponseContentDisposition).
+func ContentDisposition(dispositionType, filename string) string {
+	if i := strings.LastIndexAny(filename, `/\`); i >= 0 {
+		filename = filename[i+1:]
+	}
+	filename = strings.ToValidUTF8(filename, "\uFFFD")
+
+	fallback := make([]byte, 0, len(filename))
+	ascii := true
+	for i := 0; i < len(filename); i++ {
+		b := filename[i]
+		switch {
+		case b >= utf8.RuneSelf:
+			ascii = false
+			// Replace the whole rune with a single underscore.
+			_, size := utf8.DecodeRuneInString(filename[i:])
+			i += size - 1
+			fallback = append(fallback, '_')
+		case b < 0x20 || b == 0x7f || b == '"' || b == '\\':
+			ascii = false
+			fallback = append(fallback, '_')
+		default:
+			fallback = append(fallback, b)
+		}
+	}
+
+	var buf strings.Builder
+	buf.WriteString(dispositionType)
+	if filename == "" {
+		return buf.String()
+	}
+	buf.WriteString(`; filename="`)
+	buf.Write(fallback)
+	buf.WriteByte('"')
+	if !ascii {
+		buf.WriteString(`; filename*=UTF-8''`)
+		buf.WriteString(encodeRFC5987(filename))
+	}
+	return buf.String()
+}
+
+// encodeRFC5987 - Percent-encodes s except for RFC 5987 attr-chars.
+func encodeRFC5987(s string) string {
+	buf := make([]byte, 0, len(s)*3)
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		if isAttrChar(b) {
+			buf = append(buf, b)
+			continue
+		}
+		buf = append(buf, '%', "0123456789ABCDEF"[b>>4], "0123456789ABCDEF"[b&0xF])
+	}
+	return string(buf)
+}
+
+// isAttrChar - Reports whether b is an RFC 5987 attr-char.
+func isAttrChar(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
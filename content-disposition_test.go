@@ -0,0 +1,34 @@
+package oss_addons
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentDisposition(t *testing.T) {
+	tests := []struct {
+		dispositionType string
+		filename        string
+		expected        string
+	}{
+		{"attachment", "report.pdf", `attachment; filename="report.pdf"`},
+		{"inline", "", `inline`},
+		{"attachment", "../../etc/passwd", `attachment; filename="passwd"`},
+		{"attachment", `C:\Users\me\a b.txt`, `attachment; filename="a b.txt"`},
+		{"attachment", "报告 2017.pdf", `attachment; filename="__ 2017.pdf"; filename*=UTF-8''%E6%8A%A5%E5%91%8A%202017.pdf`},
+		{"attachment", `say "hi".txt`, `attachment; filename="say _hi_.txt"; filename*=UTF-8''say%20%22hi%22.txt`},
+		{"attachment", "a\x00\xffb", `attachment; filename="a__b"; filename*=UTF-8''a%00%EF%BF%BDb`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, ContentDisposition(tt.dispositionType, tt.filename), tt.filename)
+	}
+}
+
+func TestPostPolicySetContentDisposition(t *testing.T) {
+	policy := NewPostPolicy()
+	value := ContentDisposition("attachment", "报告.pdf")
+	assert.NoError(t, policy.SetContentDisposition(value))
+	assert.Equal(t, value, policy.formData["Content-Disposition"])
+	assert.Error(t, policy.SetContentDisposition(""))
+}
@@ -1,13 +1,36 @@
 package oss_addons
 
+import (
+	"errors"
+	"fmt"
+)
+
+// InvalidArgumentError - Returned when an argument fails validation.
 type InvalidArgumentError struct {
+	// Arg is the name of the invalid argument, empty if unknown.
+	Arg string
+
 	msg string
+	err error
 }
 
 func (e *InvalidArgumentError) Error() string {
 	return e.msg
 }
 
+// Unwrap returns the underlying cause, if any.
+func (e *InvalidArgumentError) Unwrap() error {
+	return e.err
+}
+
 func NewInvalidArgumentError(message string) error {
-	return &InvalidArgumentError{message}
+	return &InvalidArgumentError{msg: message}
+}
+
+// NewInvalidArgumentErrorf - Formats an InvalidArgumentError for argument
+// arg. As with fmt.Errorf, a %w verb in format sets the error returned by
+// Unwrap.
+func NewInvalidArgumentErrorf(arg, format string, args ...interface{}) error {
+	err := fmt.Errorf(format, args...)
+	return &InvalidArgumentError{Arg: arg, msg: err.Error(), err: errors.Unwrap(err)}
 }
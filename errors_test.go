@@ -0,0 +1,63 @@
+package oss_addons
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvalidArgumentError(t *testing.T) {
+	cause := errors.New("boom")
+	err := NewInvalidArgumentErrorf("key", "object name %q is invalid: %w", "a", cause)
+	assert.EqualError(t, err, `object name "a" is invalid: boom`)
+	assert.True(t, errors.Is(err, cause))
+
+	var argErr *InvalidArgumentError
+	if assert.True(t, errors.As(err, &argErr)) {
+		assert.Equal(t, "key", argErr.Arg)
+	}
+
+	assert.Nil(t, errors.Unwrap(NewInvalidArgumentError("object name is empty")))
+}
+
+func TestInvalidArgumentErrorArg(t *testing.T) {
+	policy := NewPostPolicy()
+	err := policy.SetContentLengthRange(-1, 10)
+	if assert.IsType(t, &InvalidArgumentError{}, err) {
+		assert.Equal(t, "min", err.(*InvalidArgumentError).Arg)
+	}
+	err = policy.SetSuccessActionRedirect("https://evil.com/", "example.com")
+	if assert.IsType(t, &InvalidArgumentError{}, err) {
+		assert.Equal(t, "redirect", err.(*InvalidArgumentError).Arg)
+	}
+
+	policy.SetStrict(true)
+	policy.SetKey("a.png")
+	err = policy.SetKey("b.png")
+	if assert.IsType(t, &InvalidArgumentError{}, err) {
+		assert.Equal(t, "key", err.(*InvalidArgumentError).Arg, "Duplicate conditions should name the setter's argument")
+	}
+	policy.SetLimits(1, DefaultMaxPolicySize)
+	err = policy.AddCondition(StartsWith("$Content-Type", "image/"))
+	if assert.IsType(t, &InvalidArgumentError{}, err) {
+		assert.Equal(t, "c", err.(*InvalidArgumentError).Arg, "Limit errors should name the setter's argument")
+	}
+	err = policy.SetUserMetadata("owner", "alice")
+	if assert.IsType(t, &InvalidArgumentError{}, err) {
+		assert.Equal(t, "key", err.(*InvalidArgumentError).Arg, "A new metadata key adds the condition over the limit")
+	}
+	err = policy.SetForbidOverwrite()
+	if assert.IsType(t, &InvalidArgumentError{}, err) {
+		assert.Empty(t, err.(*InvalidArgumentError).Arg, "SetForbidOverwrite takes no argument")
+	}
+
+	_, err = ParsePostPolicy([]byte(`{"expiration":"2017-01-23T04:05:06Z","conditions":[["content-length-range",10,1]]}`))
+	if assert.IsType(t, &InvalidArgumentError{}, err) {
+		assert.Equal(t, "data", err.(*InvalidArgumentError).Arg, "Parse errors should name ParsePostPolicy's argument")
+	}
+	_, err = ParsePostPolicy([]byte(`{"expiration":"0001-01-01T00:00:00Z","conditions":[]}`))
+	if assert.IsType(t, &InvalidArgumentError{}, err) {
+		assert.Equal(t, "data", err.(*InvalidArgumentError).Arg)
+	}
+}
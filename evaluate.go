@@ -0,0 +1,109 @@
+package oss_addons
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConditionFailedError - Returned by Evaluate when a form does not satisfy
+// a policy condition.
+type ConditionFailedError struct {
+	// MatchType is "eq", "starts-with" or "content-length-range".
+	MatchType string
+	// Condition is the checked form field, e.g. "$key".
+	Condition string
+	// Value is the expected value or prefix.
+	Value string
+}
+
+func (e *ConditionFailedError) Error() string {
+	if e.MatchType == "content-length-range" {
+		return "content length is not in range " + e.Value
+	}
+	return "condition " + e.MatchType + " " + e.Condition + " " + e.Value + " failed"
+}
+
+// DeniedFieldError - Returned by EvaluateStrict when a form carries a denied
+// field the policy has no condition on.
+type DeniedFieldError struct {
+	Field string
+}
+
+func (e *DeniedFieldError) Error() string {
+	return "form field " + e.Field + " is denied"
+}
+
+// PolicyExpiredError - Returned by Evaluate when the policy has expired.
+type PolicyExpiredError struct {
+	Expiration time.Time
+}
+
+func (e *PolicyExpiredError) Error() string {
+	return "policy expired at " + e.Expiration.UTC().Format(time.RFC3339)
+}
+
+// DefaultDeniedFields - Form fields EvaluateStrict rejects unless the policy
+// has a condition on them.
+var DefaultDeniedFields = []string{"x-oss-object-acl", "success_action_redirect"}
+
+// Evaluate - Checks locally whether an upload with the given form fields and
+// content length would be accepted under the policy at time now, so
+// frontends can be tested without uploading to OSS. Form field names are
+// matched case-insensitively, and an absent field is treated as empty.
+func Evaluate(p *PostPolicy, form map[string]string, contentLength int64, now time.Time) error {
+	if !now.Before(p.expiration) {
+		return &PolicyExpiredError{Expiration: p.expiration}
+	}
+
+	fields := make(map[string]string, len(form))
+	for k, v := range form {
+		fields[strings.ToLower(k)] = v
+	}
+
+	if p.contentLengthRange.set {
+		if contentLength < p.contentLengthRange.min || contentLength > p.contentLengthRange.max {
+			return &ConditionFailedError{
+				MatchType: "content-length-range",
+				Value:     "[" + strconv.FormatInt(p.contentLengthRange.min, 10) + ", " + strconv.FormatInt(p.contentLengthRange.max, 10) + "]",
+			}
+		}
+	}
+
+	for _, po := range p.conditions {
+		v := fields[strings.ToLower(strings.TrimPrefix(po.condition, "$"))]
+		var ok bool
+		switch po.matchType {
+		case "eq":
+			ok = v == po.value
+		case "starts-with":
+			ok = strings.HasPrefix(v, po.value)
+		}
+		if !ok {
+			return &ConditionFailedError{
+				MatchType: po.matchType,
+				Condition: po.condition,
+				Value:     po.value,
+			}
+		}
+	}
+	return nil
+}
+
+// EvaluateStrict - Like Evaluate, but also rejects a form carrying any of the
+// denied fields, e.g. DefaultDeniedFields, unless the policy explicitly
+// allows it with a condition on that field. This catches frontends sending
+// fields the backend never meant to sign for.
+func EvaluateStrict(p *PostPolicy, form map[string]string, contentLength int64, now time.Time, denied []string) error {
+	if err := Evaluate(p, form, contentLength, now); err != nil {
+		return err
+	}
+	for k := range form {
+		for _, field := range denied {
+			if strings.EqualFold(k, field) && !p.hasCondition(field) {
+				return &DeniedFieldError{Field: field}
+			}
+		}
+	}
+	return nil
+}
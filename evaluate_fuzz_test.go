@@ -0,0 +1,24 @@
+//go:build go1.18
+// +build go1.18
+
+package oss_addons
+
+import (
+	"testing"
+	"time"
+)
+
+func FuzzEvaluate(f *testing.F) {
+	f.Add([]byte(`{"expiration":"2017-01-23T04:05:06Z","conditions":[["content-length-range",1,1024],["eq","$bucket","test-bucket"],["starts-with","$key","user/1/"]]}`),
+		"key", "user/1/a.png", int64(512))
+	f.Add([]byte(`{"expiration":"2017-01-23T04:05:06Z","conditions":[["starts-with","$Content-Type","image/"]]}`),
+		"Content-Type", "image/png", int64(-1))
+	f.Fuzz(func(t *testing.T, data []byte, field, value string, contentLength int64) {
+		p, err := ParsePostPolicy(data)
+		if err != nil {
+			return
+		}
+		now := time.Date(2017, 1, 23, 0, 0, 0, 0, time.UTC)
+		Evaluate(p, map[string]string{field: value}, contentLength, now)
+	})
+}
@@ -0,0 +1,61 @@
+package oss_addons
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	expiresAt := time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC)
+	now := expiresAt.Add(-time.Minute)
+
+	policy := NewPostPolicy()
+	policy.SetExpires(expiresAt)
+	policy.SetContentLengthRange(1, 1024)
+	policy.SetBucket("test-bucket")
+	policy.SetKeyStartsWith("user/1/")
+	policy.AddCondition(StartsWith("$Content-Type", "image/"))
+
+	form := map[string]string{
+		"bucket":       "test-bucket",
+		"key":          "user/1/a.png",
+		"content-type": "image/png",
+	}
+	assert.NoError(t, Evaluate(policy, form, 512, now))
+
+	assert.IsType(t, &PolicyExpiredError{}, Evaluate(policy, form, 512, expiresAt))
+	assert.Equal(t, &ConditionFailedError{MatchType: "content-length-range", Value: "[1, 1024]"},
+		Evaluate(policy, form, 2048, now))
+
+	form["key"] = "user/2/a.png"
+	assert.Equal(t, &ConditionFailedError{MatchType: "starts-with", Condition: "$key", Value: "user/1/"},
+		Evaluate(policy, form, 512, now))
+
+	form["key"] = "user/1/a.png"
+	delete(form, "content-type")
+	assert.Equal(t, &ConditionFailedError{MatchType: "starts-with", Condition: "$Content-Type", Value: "image/"},
+		Evaluate(policy, form, 512, now))
+}
+
+func TestEvaluateStrict(t *testing.T) {
+	expiresAt := time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC)
+	now := expiresAt.Add(-time.Minute)
+
+	policy := NewPostPolicy()
+	policy.SetExpires(expiresAt)
+	policy.SetKey("a.png")
+
+	form := map[string]string{"key": "a.png"}
+	assert.NoError(t, EvaluateStrict(policy, form, 0, now, DefaultDeniedFields))
+
+	form["X-OSS-Object-Acl"] = "public-read"
+	assert.NoError(t, Evaluate(policy, form, 0, now))
+	assert.Equal(t, &DeniedFieldError{Field: "x-oss-object-acl"},
+		EvaluateStrict(policy, form, 0, now, DefaultDeniedFields))
+	assert.NoError(t, EvaluateStrict(policy, form, 0, now, nil))
+
+	policy.AddCondition(Eq("$x-oss-object-acl", "public-read"))
+	assert.NoError(t, EvaluateStrict(policy, form, 0, now, DefaultDeniedFields))
+}
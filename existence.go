@@ -0,0 +1,132 @@
+package oss_addons
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// DefaultExistenceCacheSize - Default maximum number of keys an
+// ExistenceChecker remembers, see ExistenceChecker.SetMaxEntries.
+const DefaultExistenceCacheSize = 10000
+
+// ErrObjectExists - Returned by ExistenceChecker.CheckAbsent when the object
+// is already stored.
+var ErrObjectExists = errors.New("object already exists")
+
+// objectExister - The HEAD request backend of ExistenceChecker.
+type objectExister interface {
+	IsObjectExist(key string) (bool, error)
+}
+
+// bucketExister - objectExister backed by an OSS bucket.
+type bucketExister struct {
+	bucket *oss.Bucket
+}
+
+func (b bucketExister) IsObjectExist(key string) (bool, error) {
+	return b.bucket.IsObjectExist(key)
+}
+
+// ExistenceChecker - Checks with a signed HEAD request whether an object
+// exists before a policy forbidding overwrites is issued for it, so clients
+// get ErrObjectExists up front instead of a failed upload. Objects found to
+// exist are remembered for a while, absent ones are always checked again.
+// At most DefaultExistenceCacheSize keys are remembered unless changed with
+// SetMaxEntries; when full, expired
+// keys are swept and then the key closest to expiry is forgotten.
+type ExistenceChecker struct {
+	exister    objectExister
+	ttl        time.Duration
+	maxEntries int
+
+	mu     sync.Mutex
+	exists map[string]time.Time
+}
+
+// NewExistenceChecker - Instantiate new existence checker for bucket,
+// caching positive results for ttl.
+func NewExistenceChecker(bucket *oss.Bucket, ttl time.Duration) *ExistenceChecker {
+	return newExistenceChecker(bucketExister{bucket: bucket}, ttl, DefaultExistenceCacheSize)
+}
+
+func newExistenceChecker(exister objectExister, ttl time.Duration, maxEntries int) *ExistenceChecker {
+	return &ExistenceChecker{
+		exister:    exister,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		exists:     make(map[string]time.Time),
+	}
+}
+
+// SetMaxEntries - Sets the maximum number of keys remembered, forgetting
+// keys right away if more are cached.
+func (c *ExistenceChecker) SetMaxEntries(n int) error {
+	if n <= 0 {
+		return NewInvalidArgumentErrorf("n", "maximum entries %d must be positive", n)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxEntries = n
+	now := timeNow()
+	for len(c.exists) > n {
+		c.evict(now)
+	}
+	return nil
+}
+
+// CheckAbsent - Returns ErrObjectExists if key exists in the bucket.
+func (c *ExistenceChecker) CheckAbsent(key string) error {
+	now := timeNow()
+
+	c.mu.Lock()
+	expiresAt, ok := c.exists[key]
+	if ok && now.After(expiresAt) {
+		delete(c.exists, key)
+		ok = false
+	}
+	c.mu.Unlock()
+	if ok {
+		return ErrObjectExists
+	}
+
+	exists, err := c.exister.IsObjectExist(key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	c.mu.Lock()
+	if _, ok := c.exists[key]; !ok && len(c.exists) >= c.maxEntries {
+		c.evict(now)
+	}
+	c.exists[key] = now.Add(c.ttl)
+	c.mu.Unlock()
+	return ErrObjectExists
+}
+
+// evict - Removes expired keys, or the key closest to expiry if none has
+// expired. c.mu must be held.
+func (c *ExistenceChecker) evict(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	found, swept := false, false
+	for k, expiresAt := range c.exists {
+		if now.After(expiresAt) {
+			delete(c.exists, k)
+			swept = true
+			continue
+		}
+		if !found || expiresAt.Before(oldest) {
+			oldestKey, oldest, found = k, expiresAt, true
+		}
+	}
+	if !swept && found {
+		delete(c.exists, oldestKey)
+	}
+}
@@ -0,0 +1,85 @@
+package oss_addons
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/timonwong/ali-oss-addons/osstest"
+)
+
+type fakeExister struct {
+	objects map[string]bool
+	calls   int
+}
+
+func (f *fakeExister) IsObjectExist(key string) (bool, error) {
+	f.calls++
+	return f.objects[key], nil
+}
+
+func TestExistenceChecker(t *testing.T) {
+	now := time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	f := &fakeExister{objects: map[string]bool{"a": true, "b": true, "c": true, "d": true}}
+	c := newExistenceChecker(f, time.Minute, 2)
+
+	assert.NoError(t, c.CheckAbsent("missing"))
+	assert.NoError(t, c.CheckAbsent("missing"))
+	assert.Equal(t, 2, f.calls, "Absent keys should always be checked again")
+
+	assert.Equal(t, ErrObjectExists, c.CheckAbsent("a"))
+	assert.Equal(t, ErrObjectExists, c.CheckAbsent("a"))
+	assert.Equal(t, 3, f.calls, "Existing keys should be cached")
+
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, ErrObjectExists, c.CheckAbsent("a"))
+	assert.Equal(t, 4, f.calls, "Cached keys should expire")
+
+	now = now.Add(time.Second)
+	assert.Equal(t, ErrObjectExists, c.CheckAbsent("b"))
+	assert.Equal(t, ErrObjectExists, c.CheckAbsent("c"))
+	assert.Len(t, c.exists, 2, "The cache should not grow beyond its size")
+	assert.NotContains(t, c.exists, "a", "The key closest to expiry should be evicted")
+
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, ErrObjectExists, c.CheckAbsent("d"))
+	assert.Equal(t, map[string]time.Time{"d": now.Add(time.Minute)}, c.exists, "Expired keys should be swept")
+}
+
+func TestExistenceCheckerSetMaxEntries(t *testing.T) {
+	now := time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	f := &fakeExister{objects: map[string]bool{"a": true, "b": true, "c": true}}
+	c := newExistenceChecker(f, time.Minute, DefaultExistenceCacheSize)
+	for _, key := range []string{"a", "b", "c"} {
+		assert.Equal(t, ErrObjectExists, c.CheckAbsent(key))
+		now = now.Add(time.Second)
+	}
+
+	assert.Error(t, c.SetMaxEntries(0))
+	assert.NoError(t, c.SetMaxEntries(1))
+	assert.Equal(t, 1, c.maxEntries)
+	assert.Len(t, c.exists, 1, "Shrinking should forget keys right away")
+	assert.Contains(t, c.exists, "c", "The keys closest to expiry should be forgotten first")
+}
+
+func TestExistenceCheckerIntegration(t *testing.T) {
+	bucket := osstest.RequireRealBucket(t)
+	key := osstest.ObjectKey(t)
+	defer osstest.DeleteObject(t, bucket, key)
+
+	c := NewExistenceChecker(bucket, time.Minute)
+	if !assert.NoError(t, c.CheckAbsent(key)) {
+		return
+	}
+	if !assert.NoError(t, bucket.PutObject(key, strings.NewReader("hello"))) {
+		return
+	}
+	assert.Equal(t, ErrObjectExists, c.CheckAbsent(key))
+}
@@ -0,0 +1,82 @@
+package oss_addons
+
+import (
+	"strings"
+	"time"
+)
+
+// signingScopedFields - Fields bound to the credentials and time a policy
+// was signed with, which Reissue drops.
+var signingScopedFields = append([]string{"x-oss-security-token"}, v4PolicyFields...)
+
+// LegacyPolicyReport - Result of checking a stored policy document with
+// CheckLegacyPolicy.
+type LegacyPolicyReport struct {
+	// Policy is the parsed policy, nil if Err is set.
+	Policy *PostPolicy
+	// Err describes why the document is incompatible with this package,
+	// e.g. an unsupported condition.
+	Err error
+	// Expired reports whether the policy has expired.
+	Expired bool
+	// SecurityToken is the STS security token the policy was issued with,
+	// if any. Such tokens are short-lived and most likely stale: Reissue
+	// drops it, set a current one with SetSecurityToken.
+	SecurityToken string
+}
+
+// CheckLegacyPolicy - Parses a policy JSON document issued by another
+// implementation and reports whether this package can represent it, and
+// whether it has expired at time now.
+func CheckLegacyPolicy(data []byte, now time.Time) LegacyPolicyReport {
+	p, err := ParsePostPolicy(data)
+	if err != nil {
+		return LegacyPolicyReport{Err: err}
+	}
+	r := LegacyPolicyReport{
+		Policy:  p,
+		Expired: !now.Before(p.expiration),
+	}
+	for _, po := range p.conditions {
+		if strings.EqualFold(po.condition, "$x-oss-security-token") {
+			r.SecurityToken = po.value
+		}
+	}
+	return r
+}
+
+// Reissue - Returns a copy of the parsed policy expiring at expiration,
+// ready to be signed again. Conditions bound to the previous signature, the
+// security token and the V4 fields, are dropped. It fails if the document
+// was incompatible.
+func (r LegacyPolicyReport) Reissue(expiration time.Time) (*PostPolicy, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	p := r.Policy.clone()
+	if err := p.SetExpires(expiration); err != nil {
+		return nil, err
+	}
+	p.conditions = p.conditions[:0]
+	for _, po := range r.Policy.conditions {
+		if !isSigningScopedField(strings.TrimPrefix(po.condition, "$")) {
+			p.conditions = append(p.conditions, po)
+		}
+	}
+	for field := range p.formData {
+		if isSigningScopedField(field) {
+			delete(p.formData, field)
+		}
+	}
+	return p, nil
+}
+
+// isSigningScopedField - Reports whether field is one of signingScopedFields.
+func isSigningScopedField(field string) bool {
+	for _, f := range signingScopedFields {
+		if strings.EqualFold(f, field) {
+			return true
+		}
+	}
+	return false
+}
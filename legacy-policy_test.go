@@ -0,0 +1,71 @@
+package oss_addons
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/timonwong/ali-oss-addons/testvectors"
+)
+
+func TestCheckLegacyPolicy(t *testing.T) {
+	now := time.Date(2017, 1, 23, 5, 0, 0, 0, time.UTC)
+	data := []byte(`{"expiration":"2017-01-23T04:05:06.000Z","conditions":[{"bucket":"test-bucket"},["starts-with","$key","user/1/"],["content-length-range",0,1048576]]}`)
+
+	r := CheckLegacyPolicy(data, now)
+	if !assert.NoError(t, r.Err) {
+		return
+	}
+	assert.True(t, r.Expired)
+
+	p, err := r.Reissue(now.Add(time.Hour))
+	if assert.NoError(t, err) {
+		assert.Equal(t,
+			`{"expiration":"2017-01-23T06:00:00Z","conditions":[["content-length-range",0,1048576],["eq","$bucket","test-bucket"],["starts-with","$key","user/1/"]]}`,
+			p.String())
+	}
+	assert.Equal(t, time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC), r.Policy.expiration, "The parsed policy should not be modified")
+
+	data = []byte(`{"expiration":"2017-01-23T06:00:00Z","conditions":[["starts-with","$key",""],["starts-with","$Content-Type",""]]}`)
+	r = CheckLegacyPolicy(data, now)
+	if assert.NoError(t, r.Err, "Empty prefixes should be accepted") {
+		assert.False(t, r.Expired)
+		assert.Equal(t, string(data), r.Policy.String())
+	}
+
+	r = CheckLegacyPolicy([]byte(`{"expiration":"2017-01-23T04:05:06Z","conditions":[["in","$key","a"]]}`), now)
+	assert.IsType(t, &InvalidArgumentError{}, r.Err)
+	_, err = r.Reissue(now.Add(time.Hour))
+	assert.Error(t, err)
+}
+
+func TestCheckLegacyPolicySigned(t *testing.T) {
+	now := time.Date(2017, 1, 24, 4, 5, 6, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	data := strings.Replace(testvectors.RegressionVectors[1].PolicyJSON, `"conditions":[`, `"conditions":[["eq","$x-oss-security-token","sts-token"],`, 1)
+	r := CheckLegacyPolicy([]byte(data), now)
+	if !assert.NoError(t, r.Err) {
+		return
+	}
+	assert.Equal(t, "sts-token", r.SecurityToken)
+
+	p, err := r.Reissue(now.Add(time.Hour))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t,
+		`{"expiration":"2017-01-24T05:05:06Z","conditions":[["eq","$bucket","examplebucket"],["eq","$key","报告 \"2017\".pdf"],["starts-with","$Content-Type","image/"]]}`,
+		p.String(), "Conditions bound to the previous signature should be dropped")
+	assert.Equal(t, map[string]string{"bucket": "examplebucket", "key": "报告 \"2017\".pdf"}, p.formData)
+
+	_, formData, err := PresignedPostPolicyV4(newTestClient(), p, "cn-hangzhou")
+	if assert.NoError(t, err) {
+		policyJSON, _ := base64.StdEncoding.DecodeString(formData["policy"])
+		assert.Equal(t, 1, strings.Count(string(policyJSON), "$x-oss-credential"))
+		assert.Contains(t, string(policyJSON), `["eq","$x-oss-date","20170124T040506Z"]`)
+	}
+}
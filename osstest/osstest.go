@@ -0,0 +1,72 @@
+// Package osstest provides helpers for running integration tests against a
+// real Aliyun OSS bucket.
+//
+// Integration tests are opt-in: they only run when the following environment
+// variables are set, and are skipped otherwise.
+//
+//	OSS_TEST_ENDPOINT           e.g. https://oss-cn-hangzhou.aliyuncs.com
+//	OSS_TEST_ACCESS_KEY_ID
+//	OSS_TEST_ACCESS_KEY_SECRET
+//	OSS_TEST_BUCKET
+package osstest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// Environment variables consulted by RequireRealBucket.
+const (
+	EnvEndpoint        = "OSS_TEST_ENDPOINT"
+	EnvAccessKeyID     = "OSS_TEST_ACCESS_KEY_ID"
+	EnvAccessKeySecret = "OSS_TEST_ACCESS_KEY_SECRET"
+	EnvBucket          = "OSS_TEST_BUCKET"
+)
+
+// KeyPrefix is prepended to every object key generated by ObjectKey, so
+// objects left behind by interrupted test runs are easy to find.
+const KeyPrefix = "ali-oss-addons-test/"
+
+// RequireRealBucket returns a bucket configured from the environment, or
+// skips the test if any of the required variables is missing.
+func RequireRealBucket(t testing.TB) *oss.Bucket {
+	endpoint := os.Getenv(EnvEndpoint)
+	accessKeyID := os.Getenv(EnvAccessKeyID)
+	accessKeySecret := os.Getenv(EnvAccessKeySecret)
+	bucketName := os.Getenv(EnvBucket)
+	if endpoint == "" || accessKeyID == "" || accessKeySecret == "" || bucketName == "" {
+		t.Skipf("skipping integration test: %s, %s, %s and %s must be set",
+			EnvEndpoint, EnvAccessKeyID, EnvAccessKeySecret, EnvBucket)
+	}
+
+	c, err := oss.New(endpoint, accessKeyID, accessKeySecret)
+	if err != nil {
+		t.Fatalf("osstest: unable to create client: %v", err)
+	}
+	b, err := c.Bucket(bucketName)
+	if err != nil {
+		t.Fatalf("osstest: unable to open bucket %q: %v", bucketName, err)
+	}
+	return b
+}
+
+// ObjectKey returns a random object key under KeyPrefix.
+func ObjectKey(t testing.TB) string {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		t.Fatalf("osstest: unable to generate object key: %v", err)
+	}
+	return KeyPrefix + hex.EncodeToString(buf[:])
+}
+
+// DeleteObject removes key from b, reporting (but not failing on) errors.
+// It is meant to be deferred right after an object key is allocated.
+func DeleteObject(t testing.TB, b *oss.Bucket, key string) {
+	if err := b.DeleteObject(key); err != nil {
+		t.Logf("osstest: unable to delete object %q: %v", key, err)
+	}
+}
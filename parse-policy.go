@@ -0,0 +1,117 @@
+package oss_addons
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// ParsePostPolicy - Parses a POST policy JSON document, as produced by
+// PostPolicy.String, back into a PostPolicy. Form data is filled in for
+// non-empty eq conditions and key prefixes, mirroring the setters. The input is
+// subject to the default limits, see PostPolicy.SetLimits.
+func ParsePostPolicy(data []byte) (*PostPolicy, error) {
+	if len(data) > DefaultMaxPolicySize {
+		return nil, NewInvalidArgumentErrorf("data", "policy is larger than %d bytes", DefaultMaxPolicySize)
+	}
+
+	var doc struct {
+		Expiration *string           `json:"expiration"`
+		Conditions []json.RawMessage `json:"conditions"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, NewInvalidArgumentErrorf("data", "policy is not valid JSON: %w", err)
+	}
+	if doc.Expiration == nil {
+		return nil, NewInvalidArgumentErrorf("data", "policy has no expiration")
+	}
+	if len(doc.Conditions) > DefaultMaxPolicyConditions {
+		return nil, NewInvalidArgumentErrorf("data", "policy has more than %d conditions", DefaultMaxPolicyConditions)
+	}
+
+	p := NewPostPolicy()
+	expiration, err := time.Parse(time.RFC3339Nano, *doc.Expiration)
+	if err != nil {
+		return nil, NewInvalidArgumentErrorf("data", "policy expiration is invalid: %w", err)
+	}
+	if y := expiration.UTC().Year(); y < 1 || y > 9999 {
+		return nil, NewInvalidArgumentErrorf("data", "policy expiration is out of range")
+	}
+	if err := p.SetExpires(expiration); err != nil {
+		return nil, NewInvalidArgumentErrorf("data", "policy expiration is invalid: %w", err)
+	}
+
+	for _, raw := range doc.Conditions {
+		if err := p.parseCondition(raw); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// parseCondition - Parses a condition in either the array form
+// ["eq", "$key", "value"] or the object form {"key": "value"}.
+func (p *PostPolicy) parseCondition(raw json.RawMessage) error {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '{' {
+		var m map[string]string
+		if err := json.Unmarshal(raw, &m); err != nil || len(m) != 1 {
+			return NewInvalidArgumentErrorf("data", "invalid condition %s", raw)
+		}
+		for k, v := range m {
+			return p.addParsedCondition("eq", "$"+k, v)
+		}
+	}
+
+	var parts []json.RawMessage
+	if err := json.Unmarshal(raw, &parts); err != nil || len(parts) != 3 {
+		return NewInvalidArgumentErrorf("data", "invalid condition %s", raw)
+	}
+	var matchType string
+	if err := json.Unmarshal(parts[0], &matchType); err != nil {
+		return NewInvalidArgumentErrorf("data", "invalid condition %s", raw)
+	}
+
+	if strings.ToLower(matchType) == "content-length-range" {
+		var min, max int64
+		if json.Unmarshal(parts[1], &min) != nil || json.Unmarshal(parts[2], &max) != nil {
+			return NewInvalidArgumentErrorf("data", "invalid condition %s", raw)
+		}
+		if err := p.SetContentLengthRange(min, max); err != nil {
+			return NewInvalidArgumentErrorf("data", "invalid condition %s: %w", raw, err)
+		}
+		return nil
+	}
+
+	var condition, value string
+	if json.Unmarshal(parts[1], &condition) != nil || json.Unmarshal(parts[2], &value) != nil {
+		return NewInvalidArgumentErrorf("data", "invalid condition %s", raw)
+	}
+	return p.addParsedCondition(strings.ToLower(matchType), condition, value)
+}
+
+// addParsedCondition - Adds a parsed eq or starts-with condition.
+func (p *PostPolicy) addParsedCondition(matchType, condition, value string) error {
+	if matchType != "eq" && matchType != "starts-with" {
+		return NewInvalidArgumentErrorf("data", "unsupported match type %s", matchType)
+	}
+	if !strings.HasPrefix(condition, "$") {
+		return NewInvalidArgumentErrorf("data", "condition %s must start with $", condition)
+	}
+	policyCond := Condition{
+		matchType: matchType,
+		condition: condition,
+		value:     value,
+	}
+	// Unlike the setters, empty values are valid here: an empty prefix
+	// matches anything and an empty eq matches an absent optional field.
+	if err := p.appendCondition("data", policyCond); err != nil {
+		return err
+	}
+	field := condition[1:]
+	if value != "" && (matchType == "eq" || field == "key") {
+		p.formData[field] = value
+	}
+	return nil
+}
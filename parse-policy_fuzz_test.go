@@ -0,0 +1,29 @@
+//go:build go1.18
+// +build go1.18
+
+package oss_addons
+
+import (
+	"testing"
+)
+
+func FuzzParsePostPolicy(f *testing.F) {
+	f.Add([]byte(`{"expiration":"2017-01-23T04:05:06Z","conditions":[["content-length-range",1,1024],["eq","$bucket","test-bucket"],["starts-with","$key","user/1/"]]}`))
+	f.Add([]byte(`{"expiration":"2017-01-23T12:05:06.123+08:00","conditions":[{"bucket":"test-bucket"},["EQ","$key","aé\"\\.png"]]}`))
+	f.Add([]byte(`{"expiration":"2017-01-23T04:05:06Z","conditions":[]}`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		p, err := ParsePostPolicy(data)
+		if err != nil {
+			return
+		}
+		// Whatever parses must survive a round trip.
+		s := p.String()
+		p2, err := ParsePostPolicy([]byte(s))
+		if err != nil {
+			t.Fatalf("unable to parse %s: %v", s, err)
+		}
+		if s2 := p2.String(); s != s2 {
+			t.Fatalf("round trip mismatch: %s != %s", s, s2)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package oss_addons
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/timonwong/ali-oss-addons/testvectors"
+)
+
+func TestParsePostPolicy(t *testing.T) {
+	policy := NewPostPolicy()
+	policy.SetExpires(time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC))
+	policy.SetContentLengthRange(1, 1024)
+	policy.SetBucket("test-bucket")
+	policy.SetKeyStartsWith("user/1/")
+	policy.AddCondition(StartsWith("$Content-Type", "image/"))
+
+	parsed, err := ParsePostPolicy([]byte(policy.String()))
+	if assert.NoError(t, err) {
+		assert.Equal(t, policy.String(), parsed.String())
+		assert.Equal(t, policy.formData, parsed.formData)
+	}
+
+	parsed, err = ParsePostPolicy([]byte(`{"expiration":"2017-01-23T12:05:06+08:00","conditions":[{"bucket":"test-bucket"},["EQ","$key","a.png"]]}`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"expiration":"2017-01-23T04:05:06Z","conditions":[["eq","$bucket","test-bucket"],["eq","$key","a.png"]]}`, parsed.String())
+		assert.Equal(t, map[string]string{"bucket": "test-bucket", "key": "a.png"}, parsed.formData)
+	}
+
+	data := `{"expiration":"2017-01-23T04:05:06Z","conditions":[["starts-with","$key",""],["eq","$success_action_redirect",""]]}`
+	parsed, err = ParsePostPolicy([]byte(data))
+	if assert.NoError(t, err, "Empty values should be accepted") {
+		assert.Equal(t, data, parsed.String())
+		assert.Empty(t, parsed.formData)
+
+		now := time.Date(2017, 1, 23, 0, 0, 0, 0, time.UTC)
+		assert.NoError(t, Evaluate(parsed, map[string]string{"key": "a.png"}, 0, now))
+		assert.Error(t, Evaluate(parsed, map[string]string{"key": "a.png", "success_action_redirect": "https://example.com/"}, 0, now))
+	}
+}
+
+func TestParsePostPolicyInvalid(t *testing.T) {
+	for _, data := range []string{
+		``,
+		`[]`,
+		`{"conditions":[]}`,
+		`{"expiration":"tomorrow","conditions":[]}`,
+		`{"expiration":"2017-01-23T04:05:06Z","conditions":[["eq","$key"]]}`,
+		`{"expiration":"2017-01-23T04:05:06Z","conditions":[["in","$key","a"]]}`,
+		`{"expiration":"2017-01-23T04:05:06Z","conditions":[["eq","key","a"]]}`,
+		`{"expiration":"2017-01-23T04:05:06Z","conditions":[["content-length-range",10,1]]}`,
+		`{"expiration":"2017-01-23T04:05:06Z","conditions":[{"key":"a","bucket":"b"}]}`,
+	} {
+		_, err := ParsePostPolicy([]byte(data))
+		assert.IsType(t, &InvalidArgumentError{}, err, data)
+	}
+}
+
+func TestParsePostPolicyTestVectors(t *testing.T) {
+	for _, v := range testvectors.RegressionVectors {
+		p, err := ParsePostPolicy([]byte(v.PolicyJSON))
+		if assert.NoError(t, err, v.Name) {
+			assert.Equal(t, v.PolicyJSON, p.String(), "PostPolicy should serialize %s canonically", v.Name)
+		}
+	}
+}
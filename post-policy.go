@@ -2,6 +2,8 @@ package oss_addons
 
 import (
 	"encoding/base64"
+	"encoding/json"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -11,41 +13,80 @@ import (
 // For JSON-escaping; see safeAppendString below.
 const _hex = "0123456789abcdef"
 
-// expirationDateFormat date format for expiration key in json policy.
-const expirationDateFormat = "2006-01-02T15:04:05.999Z"
+// Default limits of a policy, see PostPolicy.SetLimits.
+const (
+	DefaultMaxPolicyConditions = 256
+	DefaultMaxPolicySize       = 64 * 1024
+)
 
-// policyCondition explanation:
-// https://help.aliyun.com/document_detail/31988.html
-// https://yq.aliyun.com/articles/58524
-//
-// Example:
-//
-//   policyCondition {
-//       matchType: "$eq",
-//       key: "$Content-Type",
-//       value: "image/png",
-//   }
-//
-type policyCondition struct {
-	matchType string
-	condition string
-	value     string
+// Per-field limits documented by OSS.
+const (
+	// MaxKeyLength is the maximum length of object names in bytes.
+	MaxKeyLength = 1023
+	// MaxUserMetadataSize is the maximum total size in bytes of user
+	// metadata names (including the x-oss-meta- prefix) and values.
+	MaxUserMetadataSize = 8 * 1024
+)
+
+// knownPolicyFields are the form fields strict policies may have conditions
+// on, in lower case.
+var knownPolicyFields = map[string]bool{
+	"bucket":                       true,
+	"key":                          true,
+	"cache-control":                true,
+	"content-type":                 true,
+	"content-disposition":          true,
+	"content-encoding":             true,
+	"expires":                      true,
+	"success_action_status":        true,
+	"success_action_redirect":      true,
+	"callback":                     true,
+	"callback-var":                 true,
+	"x-oss-security-token":         true,
+	"x-oss-server-side-encryption": true,
+	"x-oss-object-acl":             true,
+	"x-oss-storage-class":          true,
+	"x-oss-forbid-overwrite":       true,
+	"x-oss-signature-version":      true,
+	"x-oss-credential":             true,
+	"x-oss-date":                   true,
 }
 
+// knownPolicyFieldPrefixes are the prefixes of user defined form fields
+// strict policies may have conditions on.
+var knownPolicyFieldPrefixes = []string{"x-oss-meta-", "x:"}
+
+// expirationDateFormat date format for expiration key in json policy.
+const expirationDateFormat = "2006-01-02T15:04:05.999Z"
+
 // PostPolicy - Provides strict static type conversion and validation
 // for Aliyun OSS POST policy JSON string.
 type PostPolicy struct {
 	// Expiration date and time of the POST policy.
 	expiration time.Time
+	// Serialize expiration as is, instead of converting it to UTC first.
+	expirationPreNormalized bool
 	// Collection of different policy conditions.
-	conditions []policyCondition
+	conditions []Condition
 	// ContentLengthRange minimum and maximum allowable size for the
 	// uploaded content.
 	contentLengthRange struct {
+		set bool
 		min int64
 		max int64
 	}
 
+	// Reject unknown and duplicate conditions, and require size and type
+	// constraints.
+	strict bool
+
+	// Maximum number of conditions and serialized size, zero means the
+	// default.
+	limits struct {
+		maxConditions int
+		maxSize       int
+	}
+
 	// Post form data.
 	formData map[string]string
 }
@@ -53,31 +94,143 @@ type PostPolicy struct {
 // NewPostPolicy - Instantiate new post policy.
 func NewPostPolicy() *PostPolicy {
 	p := &PostPolicy{}
-	p.conditions = make([]policyCondition, 0)
+	p.conditions = make([]Condition, 0)
 	p.formData = make(map[string]string)
 	return p
 }
 
+// clone - Returns a copy of the policy that can be modified independently.
+func (p *PostPolicy) clone() *PostPolicy {
+	c := *p
+	c.conditions = append([]Condition(nil), p.conditions...)
+	c.formData = copyFormData(p.formData)
+	return &c
+}
+
 // SetExpires - Sets expiration time for the new policy.
 func (p *PostPolicy) SetExpires(t time.Time) error {
 	if t.IsZero() {
-		return NewInvalidArgumentError("no expiry time set")
+		return NewInvalidArgumentErrorf("t", "no expiry time set")
 	}
 	p.expiration = t
 	return nil
 }
 
+// SetExpiresPreNormalized - Serialize the expiration time as is, without
+// converting it to UTC. For callers whose time values already carry UTC wall
+// clock regardless of their location.
+func (p *PostPolicy) SetExpiresPreNormalized(preNormalized bool) {
+	p.expirationPreNormalized = preNormalized
+}
+
+// SetStrict - Switches strict mode on or off. Strict policies reject
+// conditions on unknown form fields and more than one condition per field,
+// and can only be signed with a content length range and a Content-Type
+// condition. Lenient mode, the default, accepts any condition.
+func (p *PostPolicy) SetStrict(strict bool) error {
+	if strict {
+		for i, po := range p.conditions {
+			if err := checkStrictCondition("strict", p.conditions[:i], po); err != nil {
+				return err
+			}
+		}
+	}
+	p.strict = strict
+	return nil
+}
+
+// checkStrictCondition - Checks that policyCond is on a known field without
+// a condition in conditions yet.
+func checkStrictCondition(arg string, conditions []Condition, policyCond Condition) error {
+	field := strings.ToLower(strings.TrimPrefix(policyCond.condition, "$"))
+	if !isKnownPolicyField(field) {
+		return NewInvalidArgumentErrorf(arg, "unknown condition %s", policyCond.condition)
+	}
+	for _, po := range conditions {
+		if strings.EqualFold(po.condition, policyCond.condition) {
+			return NewInvalidArgumentErrorf(arg, "duplicate condition %s", policyCond.condition)
+		}
+	}
+	return nil
+}
+
+// isKnownPolicyField - Reports whether the lower case form field is known.
+func isKnownPolicyField(field string) bool {
+	if knownPolicyFields[field] {
+		return true
+	}
+	for _, prefix := range knownPolicyFieldPrefixes {
+		if strings.HasPrefix(field, prefix) && len(field) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateStrict - Checks the constraints strict policies must have.
+func (p *PostPolicy) validateStrict() error {
+	if !p.strict {
+		return nil
+	}
+	if !p.contentLengthRange.set {
+		return NewInvalidArgumentErrorf("p", "strict policy has no content length range")
+	}
+	for _, po := range p.conditions {
+		if strings.EqualFold(po.condition, "$Content-Type") {
+			return nil
+		}
+	}
+	return NewInvalidArgumentErrorf("p", "strict policy has no Content-Type condition")
+}
+
+// SetLimits - Sets the maximum number of conditions and the maximum size in
+// bytes of the serialized policy JSON. Zero selects DefaultMaxPolicyConditions
+// and DefaultMaxPolicySize respectively.
+func (p *PostPolicy) SetLimits(maxConditions, maxSize int) error {
+	if maxConditions < 0 {
+		return NewInvalidArgumentErrorf("maxConditions", "maximum number of conditions cannot be negative")
+	}
+	if maxSize < 0 {
+		return NewInvalidArgumentErrorf("maxSize", "maximum policy size cannot be negative")
+	}
+	if maxConditions != 0 && len(p.conditions) > maxConditions {
+		return NewInvalidArgumentErrorf("maxConditions", "policy already has more than %d conditions", maxConditions)
+	}
+	p.limits.maxConditions = maxConditions
+	p.limits.maxSize = maxSize
+	return nil
+}
+
+// maxConditions - Returns the effective maximum number of conditions.
+func (p *PostPolicy) maxConditions() int {
+	if p.limits.maxConditions == 0 {
+		return DefaultMaxPolicyConditions
+	}
+	return p.limits.maxConditions
+}
+
+// maxSize - Returns the effective maximum serialized policy size.
+func (p *PostPolicy) maxSize() int {
+	if p.limits.maxSize == 0 {
+		return DefaultMaxPolicySize
+	}
+	return p.limits.maxSize
+}
+
 // SetKey - Sets an object name for the policy based upload.
 func (p *PostPolicy) SetKey(key string) error {
 	if strings.TrimSpace(key) == "" || key == "" {
-		return NewInvalidArgumentError("object name is empty")
+		return NewInvalidArgumentErrorf("key", "object name is empty")
+	}
+	if len(key) > MaxKeyLength {
+		return NewInvalidArgumentErrorf("key", "object name is %d bytes, longer than the %d bytes allowed", len(key), MaxKeyLength)
 	}
-	policyCond := policyCondition{
+	policyCond := Condition{
 		matchType: "eq",
 		condition: "$key",
 		value:     key,
 	}
-	if err := p.addNewPolicy(policyCond); err != nil {
+	if err := p.addNewPolicy("key", policyCond); err != nil {
 		return err
 	}
 	p.formData["key"] = key
@@ -88,14 +241,17 @@ func (p *PostPolicy) SetKey(key string) error {
 // can start with.
 func (p *PostPolicy) SetKeyStartsWith(keyStartsWith string) error {
 	if strings.TrimSpace(keyStartsWith) == "" || keyStartsWith == "" {
-		return NewInvalidArgumentError("object prefix is empty")
+		return NewInvalidArgumentErrorf("keyStartsWith", "object prefix is empty")
+	}
+	if len(keyStartsWith) > MaxKeyLength {
+		return NewInvalidArgumentErrorf("keyStartsWith", "object prefix is %d bytes, longer than the %d bytes allowed", len(keyStartsWith), MaxKeyLength)
 	}
-	policyCond := policyCondition{
+	policyCond := Condition{
 		matchType: "starts-with",
 		condition: "$key",
 		value:     keyStartsWith,
 	}
-	if err := p.addNewPolicy(policyCond); err != nil {
+	if err := p.addNewPolicy("keyStartsWith", policyCond); err != nil {
 		return err
 	}
 	p.formData["key"] = keyStartsWith
@@ -105,14 +261,14 @@ func (p *PostPolicy) SetKeyStartsWith(keyStartsWith string) error {
 // SetBucket - Sets bucket at which objects will be uploaded to.
 func (p *PostPolicy) SetBucket(bucketName string) error {
 	if strings.TrimSpace(bucketName) == "" || bucketName == "" {
-		return NewInvalidArgumentError("bucket name is empty")
+		return NewInvalidArgumentErrorf("bucketName", "bucket name is empty")
 	}
-	policyCond := policyCondition{
+	policyCond := Condition{
 		matchType: "eq",
 		condition: "$bucket",
 		value:     bucketName,
 	}
-	if err := p.addNewPolicy(policyCond); err != nil {
+	if err := p.addNewPolicy("bucketName", policyCond); err != nil {
 		return err
 	}
 	p.formData["bucket"] = bucketName
@@ -123,32 +279,51 @@ func (p *PostPolicy) SetBucket(bucketName string) error {
 // based upload.
 func (p *PostPolicy) SetContentType(contentType string) error {
 	if strings.TrimSpace(contentType) == "" || contentType == "" {
-		return NewInvalidArgumentError("no content type specified")
+		return NewInvalidArgumentErrorf("contentType", "no content type specified")
 	}
-	policyCond := policyCondition{
+	policyCond := Condition{
 		matchType: "eq",
 		condition: "$Content-Type",
 		value:     contentType,
 	}
-	if err := p.addNewPolicy(policyCond); err != nil {
+	if err := p.addNewPolicy("contentType", policyCond); err != nil {
 		return err
 	}
 	p.formData["Content-Type"] = contentType
 	return nil
 }
 
+// SetContentDisposition - Sets content-disposition of the object for this
+// policy based upload, see ContentDisposition for building the value.
+func (p *PostPolicy) SetContentDisposition(contentDisposition string) error {
+	if strings.TrimSpace(contentDisposition) == "" || contentDisposition == "" {
+		return NewInvalidArgumentErrorf("contentDisposition", "no content disposition specified")
+	}
+	policyCond := Condition{
+		matchType: "eq",
+		condition: "$Content-Disposition",
+		value:     contentDisposition,
+	}
+	if err := p.addNewPolicy("contentDisposition", policyCond); err != nil {
+		return err
+	}
+	p.formData["Content-Disposition"] = contentDisposition
+	return nil
+}
+
 // SetContentLengthRange - Set new min and max content length
 // condition for all incoming uploads.
 func (p *PostPolicy) SetContentLengthRange(min, max int64) error {
 	if min > max {
-		return NewInvalidArgumentError("minimum limit is larger than maximum limit")
+		return NewInvalidArgumentErrorf("min", "minimum limit is larger than maximum limit")
 	}
 	if min < 0 {
-		return NewInvalidArgumentError("minimum limit cannot be negative")
+		return NewInvalidArgumentErrorf("min", "minimum limit cannot be negative")
 	}
 	if max < 0 {
-		return NewInvalidArgumentError("maximum limit cannot be negative")
+		return NewInvalidArgumentErrorf("max", "maximum limit cannot be negative")
 	}
+	p.contentLengthRange.set = true
 	p.contentLengthRange.min = min
 	p.contentLengthRange.max = max
 	return nil
@@ -158,29 +333,263 @@ func (p *PostPolicy) SetContentLengthRange(min, max int64) error {
 // based upload.
 func (p *PostPolicy) SetSuccessStatusAction(status string) error {
 	if strings.TrimSpace(status) == "" || status == "" {
-		return NewInvalidArgumentError("status is empty")
+		return NewInvalidArgumentErrorf("status", "status is empty")
 	}
-	policyCond := policyCondition{
+	policyCond := Condition{
 		matchType: "eq",
 		condition: "$success_action_status",
 		value:     status,
 	}
-	if err := p.addNewPolicy(policyCond); err != nil {
+	if err := p.addNewPolicy("status", policyCond); err != nil {
 		return err
 	}
 	p.formData["success_action_status"] = status
 	return nil
 }
 
-// addNewPolicy - internal helper to validate adding new policies.
-func (p *PostPolicy) addNewPolicy(policyCond policyCondition) error {
+// SetSuccessActionRedirect - Sets the URL the client is redirected to after
+// a successful upload. The URL must be an absolute http(s) URL, and if
+// allowedHosts is given its host must be one of them.
+func (p *PostPolicy) SetSuccessActionRedirect(redirect string, allowedHosts ...string) error {
+	if strings.TrimSpace(redirect) == "" || redirect == "" {
+		return NewInvalidArgumentErrorf("redirect", "redirect url is empty")
+	}
+	u, err := url.Parse(redirect)
+	if err != nil {
+		return NewInvalidArgumentErrorf("redirect", "redirect url is invalid: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return NewInvalidArgumentErrorf("redirect", "redirect url must be an absolute http or https url")
+	}
+	if u.Host == "" {
+		return NewInvalidArgumentErrorf("redirect", "redirect url has no host")
+	}
+	if len(allowedHosts) > 0 && !containsHost(allowedHosts, u.Hostname()) {
+		return NewInvalidArgumentErrorf("redirect", "redirect url host %s is not allowed", u.Hostname())
+	}
+	policyCond := Condition{
+		matchType: "eq",
+		condition: "$success_action_redirect",
+		value:     redirect,
+	}
+	if err := p.addNewPolicy("redirect", policyCond); err != nil {
+		return err
+	}
+	p.formData["success_action_redirect"] = redirect
+	return nil
+}
+
+// SetForbidOverwrite - Makes the upload fail if an object with the same key
+// exists, see also ExistenceChecker.
+func (p *PostPolicy) SetForbidOverwrite() error {
+	policyCond := Condition{
+		matchType: "eq",
+		condition: "$x-oss-forbid-overwrite",
+		value:     "true",
+	}
+	// No argument to blame, see InvalidArgumentError.Arg.
+	if err := p.addNewPolicy("", policyCond); err != nil {
+		return err
+	}
+	p.formData["x-oss-forbid-overwrite"] = "true"
+	return nil
+}
+
+// SetSecurityToken - Sets the STS security token for policies signed
+// with temporary credentials.
+func (p *PostPolicy) SetSecurityToken(token string) error {
+	if strings.TrimSpace(token) == "" || token == "" {
+		return NewInvalidArgumentErrorf("token", "security token is empty")
+	}
+	policyCond := Condition{
+		matchType: "eq",
+		condition: "$x-oss-security-token",
+		value:     token,
+	}
+	if err := p.addNewPolicy("token", policyCond); err != nil {
+		return err
+	}
+	p.formData["x-oss-security-token"] = token
+	return nil
+}
+
+// SetCallback - Sets the upload callback, callbackJSON is the callback
+// parameter JSON (callbackUrl, callbackBody, ...), which is base64 encoded
+// into the callback form field.
+func (p *PostPolicy) SetCallback(callbackJSON string) error {
+	if strings.TrimSpace(callbackJSON) == "" || callbackJSON == "" {
+		return NewInvalidArgumentErrorf("callbackJSON", "callback is empty")
+	}
+	if !json.Valid([]byte(callbackJSON)) {
+		return NewInvalidArgumentErrorf("callbackJSON", "callback is not valid JSON")
+	}
+	callback := base64.StdEncoding.EncodeToString([]byte(callbackJSON))
+	policyCond := Condition{
+		matchType: "eq",
+		condition: "$callback",
+		value:     callback,
+	}
+	if err := p.addNewPolicy("callbackJSON", policyCond); err != nil {
+		return err
+	}
+	p.formData["callback"] = callback
+	return nil
+}
+
+// SetCallbackVar - Sets custom callback variables, which can be referenced
+// in the callback body. Every name must start with "x:".
+func (p *PostPolicy) SetCallbackVar(vars map[string]string) error {
+	if len(vars) == 0 {
+		return NewInvalidArgumentErrorf("vars", "callback vars are empty")
+	}
+	for name := range vars {
+		if !strings.HasPrefix(name, "x:") || len(name) == len("x:") {
+			return NewInvalidArgumentErrorf("vars", "callback var %s must start with x:", name)
+		}
+	}
+	data, err := json.Marshal(vars)
+	if err != nil {
+		return err
+	}
+	callbackVar := base64.StdEncoding.EncodeToString(data)
+	policyCond := Condition{
+		matchType: "eq",
+		condition: "$callback-var",
+		value:     callbackVar,
+	}
+	if err := p.addNewPolicy("vars", policyCond); err != nil {
+		return err
+	}
+	p.formData["callback-var"] = callbackVar
+	return nil
+}
+
+// SetUserMetadata - Sets user metadata, uploaded as the form field
+// x-oss-meta-<key>. Setting a key again replaces its value.
+func (p *PostPolicy) SetUserMetadata(key, value string) error {
+	if strings.TrimSpace(key) == "" || key == "" {
+		return NewInvalidArgumentErrorf("key", "metadata key is empty")
+	}
+	if strings.TrimSpace(value) == "" || value == "" {
+		return NewInvalidArgumentErrorf("value", "metadata value is empty")
+	}
+	field := "x-oss-meta-" + strings.ToLower(key)
+	size := p.userMetadataSize() + len(field) + len(value)
+	if old, ok := p.formData[field]; ok {
+		// The old value is replaced.
+		size -= len(field) + len(old)
+	}
+	if size > MaxUserMetadataSize {
+		return NewInvalidArgumentErrorf("value", "user metadata would be %d bytes, larger than the %d bytes allowed", size, MaxUserMetadataSize)
+	}
+	policyCond := Condition{
+		matchType: "eq",
+		condition: "$" + field,
+		value:     value,
+	}
+	if !p.replaceCondition(policyCond) {
+		if err := p.addNewPolicy("key", policyCond); err != nil {
+			return err
+		}
+	}
+	p.formData[field] = value
+	return nil
+}
+
+// userMetadataSize - Returns the total size of user metadata names and values.
+func (p *PostPolicy) userMetadataSize() int {
+	n := 0
+	for k, v := range p.formData {
+		if strings.HasPrefix(k, "x-oss-meta-") {
+			n += len(k) + len(v)
+		}
+	}
+	return n
+}
+
+// AddCondition - Adds a condition built with Eq, StartsWith or LengthRange.
+// Unlike the other setters it doesn't set form data, the client is expected
+// to send a matching field. An empty StartsWith prefix allows any value.
+func (p *PostPolicy) AddCondition(c Condition) error {
+	if c.matchType == "content-length-range" {
+		return p.SetContentLengthRange(c.min, c.max)
+	}
+	if strings.TrimSpace(strings.TrimPrefix(c.condition, "$")) == "" {
+		return NewInvalidArgumentErrorf("c", "condition field is empty")
+	}
+	if c.matchType == "starts-with" {
+		return p.appendCondition("c", c)
+	}
+	return p.addNewPolicy("c", c)
+}
+
+// containsHost - Reports whether host is one of hosts, ignoring case.
+func containsHost(hosts []string, host string) bool {
+	for _, h := range hosts {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceCondition - Replaces the value of an existing condition with the
+// same match type and field, reporting whether there was one.
+func (p *PostPolicy) replaceCondition(policyCond Condition) bool {
+	for i, po := range p.conditions {
+		if po.matchType == policyCond.matchType && strings.EqualFold(po.condition, policyCond.condition) {
+			p.conditions[i].value = policyCond.value
+			return true
+		}
+	}
+	return false
+}
+
+// hasCondition - Reports whether the policy has a condition on field.
+func (p *PostPolicy) hasCondition(field string) bool {
+	for _, po := range p.conditions {
+		if strings.EqualFold(po.condition, "$"+field) {
+			return true
+		}
+	}
+	return false
+}
+
+// addNewPolicy - internal helper to validate adding new policies. Errors
+// name arg, the caller's argument the condition was built from.
+func (p *PostPolicy) addNewPolicy(arg string, policyCond Condition) error {
 	if policyCond.matchType == "" || policyCond.condition == "" || policyCond.value == "" {
-		return NewInvalidArgumentError("policy fields are empty")
+		return NewInvalidArgumentErrorf(arg, "policy fields are empty")
+	}
+	return p.appendCondition(arg, policyCond)
+}
+
+// appendCondition - internal helper to add a condition after the strict
+// mode and limit checks. Unlike addNewPolicy, it accepts empty values.
+func (p *PostPolicy) appendCondition(arg string, policyCond Condition) error {
+	if p.strict {
+		if err := checkStrictCondition(arg, p.conditions, policyCond); err != nil {
+			return err
+		}
+	}
+	if len(p.conditions) >= p.maxConditions() {
+		return NewInvalidArgumentErrorf(arg, "policy cannot have more than %d conditions", p.maxConditions())
 	}
 	p.conditions = append(p.conditions, policyCond)
 	return nil
 }
 
+// validateSize - internal helper to check the serialized policy size.
+func (p *PostPolicy) validateSize() error {
+	if p.marshaledSize() <= p.maxSize() {
+		return nil
+	}
+	if n := len(p.marshalJSON()); n > p.maxSize() {
+		return NewInvalidArgumentErrorf("p", "policy size %d exceeds the limit of %d bytes", n, p.maxSize())
+	}
+	return nil
+}
+
 // Stringer interface for printing policy in json formatted string.
 func (p PostPolicy) String() string {
 	return string(p.marshalJSON())
@@ -188,17 +597,21 @@ func (p PostPolicy) String() string {
 
 // marshalJSON - Provides Marshaled JSON in bytes.
 func (p PostPolicy) marshalJSON() []byte {
-	buf := make([]byte, 0, 1024) // reserve 1k buffer
+	buf := make([]byte, 0, p.marshaledSize())
 
 	// Expiration
 	buf = append(buf, `{"expiration":"`...)
-	buf = p.expiration.AppendFormat(buf, expirationDateFormat)
+	expiration := p.expiration
+	if !p.expirationPreNormalized {
+		expiration = expiration.UTC()
+	}
+	buf = expiration.AppendFormat(buf, expirationDateFormat)
 	buf = append(buf, `","conditions":[`...)
 
 	// Conditions
 	insertComma := false
 	// Content-Length-Range
-	if p.contentLengthRange.min != 0 || p.contentLengthRange.max != 0 {
+	if p.contentLengthRange.set {
 		buf = append(buf, `["content-length-range",`...)
 		buf = strconv.AppendInt(buf, p.contentLengthRange.min, 10)
 		buf = append(buf, ',')
@@ -218,11 +631,41 @@ func (p PostPolicy) marshalJSON() []byte {
 		buf = append(buf, `","`...)
 		buf = safeAppendString(buf, po.value)
 		buf = append(buf, `"]`...)
+		insertComma = true
 	}
 	buf = append(buf, `]}`...)
 	return buf
 }
 
+// marshaledSize - Computes an upper bound of the length of marshalJSON's
+// output, so the buffer is allocated once regardless of the policy size.
+func (p PostPolicy) marshaledSize() int {
+	n := len(`{"expiration":"`) + len(expirationDateFormat) + len(`","conditions":[`)
+	if p.contentLengthRange.set {
+		n += len(`["content-length-range",`) + len(`,`) + len(`]`)
+		n += int64Len(p.contentLengthRange.min) + int64Len(p.contentLengthRange.max)
+		n++ // comma, maybe unused
+	}
+	for _, po := range p.conditions {
+		n += len(`["`) + len(po.matchType) + len(`","`) + safeStringLen(po.condition) +
+			len(`","`) + safeStringLen(po.value) + len(`"]`) + len(`,`)
+	}
+	n += len(`]}`)
+	return n
+}
+
+// int64Len returns the number of bytes strconv.AppendInt(buf, i, 10) appends.
+func int64Len(i int64) int {
+	n := 1
+	if i < 0 {
+		n++
+	}
+	for ; i >= 10 || i <= -10; i /= 10 {
+		n++
+	}
+	return n
+}
+
 // base64 - Produces base64 of PostPolicy's Marshaled json.
 func (p PostPolicy) base64() string {
 	return base64.StdEncoding.EncodeToString(p.marshalJSON())
@@ -278,6 +721,37 @@ func tryAddRuneSelf(buf []byte, b byte) ([]byte, bool) {
 	return buf, true
 }
 
+// safeStringLen returns the number of bytes safeAppendString appends for s.
+func safeStringLen(s string) int {
+	n := 0
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			switch {
+			case 0x20 <= b && b != '\\' && b != '"':
+				n++
+			case b == '\\', b == '"', b == '\n', b == '\r', b == '\t':
+				n += 2
+			default:
+				n += len(`\u00XX`)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			n += len(`\ufffd`)
+			i++
+			continue
+		}
+		n += size
+		i += size
+	}
+	return n
+}
+
+// tryAppendRuneError appends the escaped replacement character if r is an
+// invalid UTF-8 sequence.
 func tryAppendRuneError(buf []byte, r rune, size int) ([]byte, bool) {
 	if r == utf8.RuneError && size == 1 {
 		buf = append(buf, `\ufffd`...)
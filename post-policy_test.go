@@ -2,8 +2,11 @@ package oss_addons
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"math/rand"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -68,3 +71,235 @@ func TestPostPolicy(t *testing.T) {
 		}
 	}
 }
+
+func TestPostPolicyMarshalJSONWithoutContentLengthRange(t *testing.T) {
+	policy := NewPostPolicy()
+	policy.SetExpires(time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC))
+	policy.SetBucket("test-bucket")
+	policy.SetKey("test-object-name")
+
+	var o policyJSON
+	err := json.Unmarshal(policy.marshalJSON(), &o)
+	if assert.NoError(t, err, "The post policy should be a valid JSON string") {
+		assert.Len(t, o.Conditions, 2)
+	}
+}
+
+func TestPostPolicyMarshaledSize(t *testing.T) {
+	policy := newLargePostPolicy(200)
+	policy.SetKey("\x00\t\"\\\xff日本語")
+
+	jsonData := policy.marshalJSON()
+	assert.True(t, len(jsonData) <= policy.marshaledSize(),
+		"marshaledSize %d should not be smaller than the output %d", policy.marshaledSize(), len(jsonData))
+	assert.Equal(t, cap(jsonData), policy.marshaledSize(), "The buffer should never grow")
+
+	allocs := testing.AllocsPerRun(10, func() { policy.marshalJSON() })
+	assert.Equal(t, 1.0, allocs)
+}
+
+func newLargePostPolicy(n int) *PostPolicy {
+	policy := NewPostPolicy()
+	policy.SetExpires(time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC))
+	policy.SetContentLengthRange(1, 1024*1024)
+	policy.SetBucket("test-bucket")
+	for i := 0; i < n; i++ {
+		policy.SetKeyStartsWith("user/" + strconv.Itoa(i) + "/uploads/")
+	}
+	return policy
+}
+
+func BenchmarkPostPolicyMarshalJSON(b *testing.B) {
+	for _, n := range []int{1, 10, 100} {
+		policy := newLargePostPolicy(n)
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				policy.marshalJSON()
+			}
+		})
+	}
+}
+
+func TestPostPolicyExpirationNormalization(t *testing.T) {
+	expiresAt := time.Date(2017, 1, 23, 12, 5, 6, 0, time.FixedZone("CST", 8*60*60))
+
+	policy := NewPostPolicy()
+	policy.SetExpires(expiresAt)
+	assert.Contains(t, policy.String(), `"expiration":"2017-01-23T04:05:06Z"`)
+
+	policy.SetExpiresPreNormalized(true)
+	assert.Contains(t, policy.String(), `"expiration":"2017-01-23T12:05:06Z"`)
+}
+
+func TestPostPolicySetSuccessActionRedirect(t *testing.T) {
+	tests := []struct {
+		redirect     string
+		allowedHosts []string
+		valid        bool
+	}{
+		{"https://example.com/uploaded", nil, true},
+		{"http://example.com:8080/uploaded?id=1", []string{"EXAMPLE.com"}, true},
+		{"", nil, false},
+		{"/uploaded", nil, false},
+		{"javascript:alert(1)", nil, false},
+		{"ftp://example.com/uploaded", nil, false},
+		{"https:///uploaded", nil, false},
+		{"https://evil.com/uploaded", []string{"example.com"}, false},
+	}
+
+	for _, tt := range tests {
+		policy := NewPostPolicy()
+		err := policy.SetSuccessActionRedirect(tt.redirect, tt.allowedHosts...)
+		if tt.valid {
+			assert.NoError(t, err, tt.redirect)
+			assert.Equal(t, tt.redirect, policy.formData["success_action_redirect"])
+		} else {
+			assert.IsType(t, &InvalidArgumentError{}, err, tt.redirect)
+			assert.NotContains(t, policy.formData, "success_action_redirect")
+		}
+	}
+}
+
+func TestPostPolicySetCallback(t *testing.T) {
+	policy := NewPostPolicy()
+	callbackJSON := `{"callbackUrl":"https://example.com/callback","callbackBody":"bucket=${bucket}&object=${object}&uid=${x:uid}"}`
+	if assert.NoError(t, policy.SetCallback(callbackJSON)) {
+		decoded, _ := base64.StdEncoding.DecodeString(policy.formData["callback"])
+		assert.Equal(t, callbackJSON, string(decoded))
+	}
+	assert.Error(t, policy.SetCallback(`{"callbackUrl":`))
+
+	if assert.NoError(t, policy.SetCallbackVar(map[string]string{"x:uid": "42"})) {
+		decoded, _ := base64.StdEncoding.DecodeString(policy.formData["callback-var"])
+		assert.Equal(t, `{"x:uid":"42"}`, string(decoded))
+	}
+	assert.Error(t, policy.SetCallbackVar(map[string]string{"uid": "42"}))
+	assert.Error(t, policy.SetCallbackVar(nil))
+
+	assert.Contains(t, policy.String(), `["eq","$callback","`+policy.formData["callback"]+`"]`)
+	assert.Contains(t, policy.String(), `["eq","$callback-var","`+policy.formData["callback-var"]+`"]`)
+}
+
+func TestPostPolicyAdditionalConditions(t *testing.T) {
+	policy := NewPostPolicy()
+	assert.NoError(t, policy.SetSecurityToken("sts-token"))
+	assert.NoError(t, policy.SetForbidOverwrite())
+	assert.NoError(t, policy.SetUserMetadata("Owner", "alice"))
+	assert.NoError(t, policy.AddCondition(StartsWith("$Content-Type", "image/")))
+	assert.NoError(t, policy.AddCondition(Eq("Cache-Control", "no-cache")))
+
+	assert.Error(t, policy.AddCondition(Condition{}))
+	assert.Error(t, policy.AddCondition(Eq("$", "value")))
+	assert.Error(t, policy.AddCondition(Eq("$Cache-Control", "")))
+	assert.Error(t, policy.AddCondition(LengthRange(10, 1)))
+	assert.Error(t, policy.SetUserMetadata("", "alice"))
+	assert.Error(t, policy.SetSecurityToken(" "))
+
+	assert.Equal(t, "sts-token", policy.formData["x-oss-security-token"])
+	assert.Equal(t, "alice", policy.formData["x-oss-meta-owner"])
+	assert.NotContains(t, policy.formData, "Content-Type")
+
+	s := policy.String()
+	assert.Contains(t, s, `["eq","$x-oss-security-token","sts-token"]`)
+	assert.Contains(t, s, `["eq","$x-oss-forbid-overwrite","true"]`)
+	assert.Contains(t, s, `["eq","$x-oss-meta-owner","alice"]`)
+	assert.Contains(t, s, `["starts-with","$Content-Type","image/"]`)
+	assert.Contains(t, s, `["eq","$Cache-Control","no-cache"]`)
+}
+
+func TestPostPolicyLimits(t *testing.T) {
+	c := newTestClient()
+
+	policy := NewPostPolicy()
+	policy.SetExpires(time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC))
+	policy.SetBucket("test-bucket")
+	policy.SetKey("test-object-name")
+
+	assert.Error(t, policy.SetLimits(1, 0), "Existing conditions should exceed the limit")
+	assert.Error(t, policy.SetLimits(-1, 0))
+	if !assert.NoError(t, policy.SetLimits(3, 160)) {
+		return
+	}
+
+	assert.NoError(t, policy.SetContentType("image/png"))
+	assert.IsType(t, &InvalidArgumentError{}, policy.SetUserMetadata("owner", "alice"))
+
+	_, _, err := PresignedPostPolicyV1(c, policy)
+	assert.NoError(t, err)
+	_, _, err = PresignedPostPolicyV4(c, policy, "cn-hangzhou")
+	assert.IsType(t, &InvalidArgumentError{}, err, "V4 fields should exceed the condition limit")
+
+	policy.SetLimits(0, 64)
+	_, _, err = PresignedPostPolicyV1(c, policy)
+	assert.EqualError(t, err, "policy size 151 exceeds the limit of 64 bytes")
+}
+
+func TestPostPolicyStrict(t *testing.T) {
+	c := newTestClient()
+
+	policy := NewPostPolicy()
+	policy.SetExpires(time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC))
+	policy.SetBucket("test-bucket")
+	policy.SetKey("test-object-name")
+	policy.SetKeyStartsWith("test-")
+	assert.Error(t, policy.SetStrict(true), "Existing duplicate conditions should be rejected")
+
+	policy = NewPostPolicy()
+	policy.SetExpires(time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC))
+	if !assert.NoError(t, policy.SetStrict(true)) {
+		return
+	}
+	assert.NoError(t, policy.SetBucket("test-bucket"))
+	assert.NoError(t, policy.SetKey("test-object-name"))
+	assert.NoError(t, policy.SetUserMetadata("owner", "alice"))
+	assert.NoError(t, policy.AddCondition(Eq("$x:uid", "42")))
+	assert.Error(t, policy.SetKeyStartsWith("test-"), "Duplicate conditions should be rejected")
+	assert.Error(t, policy.AddCondition(Eq("$X-OSS-META-OWNER", "bob")), "Duplicate conditions should be rejected")
+	assert.Error(t, policy.AddCondition(Eq("$x-custom", "value")), "Unknown conditions should be rejected")
+
+	_, _, err := PresignedPostPolicyV1(c, policy)
+	assert.EqualError(t, err, "strict policy has no content length range")
+	policy.SetContentLengthRange(1, 1024)
+	_, _, err = PresignedPostPolicyV1(c, policy)
+	assert.EqualError(t, err, "strict policy has no Content-Type condition")
+	policy.AddCondition(StartsWith("$Content-Type", "image/"))
+	_, _, err = PresignedPostPolicyV1(c, policy)
+	assert.NoError(t, err)
+	_, _, err = PresignedPostPolicyV4(c, policy, "cn-hangzhou")
+	assert.NoError(t, err)
+
+	policy.SetStrict(false)
+	assert.NoError(t, policy.AddCondition(Eq("$x-custom", "value")))
+}
+
+func TestPostPolicyFieldLimits(t *testing.T) {
+	policy := NewPostPolicy()
+	assert.NoError(t, policy.SetKey(strings.Repeat("k", MaxKeyLength)))
+	assert.EqualError(t, policy.SetKey(strings.Repeat("k", MaxKeyLength+1)),
+		"object name is 1024 bytes, longer than the 1023 bytes allowed")
+	assert.IsType(t, &InvalidArgumentError{}, policy.SetKeyStartsWith(strings.Repeat("k", MaxKeyLength+1)))
+
+	value := strings.Repeat("v", 4000)
+	assert.NoError(t, policy.SetUserMetadata("a", value))
+	assert.NoError(t, policy.SetUserMetadata("b", value))
+	assert.EqualError(t, policy.SetUserMetadata("c", value),
+		"user metadata would be 12036 bytes, larger than the 8192 bytes allowed")
+	assert.NotContains(t, policy.formData, "x-oss-meta-c")
+	assert.NoError(t, policy.SetUserMetadata("A", value), "Replacing a value should not count the old one")
+
+	policy = NewPostPolicy()
+	policy.SetExpires(time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC))
+	policy.SetStrict(true)
+	assert.NoError(t, policy.SetUserMetadata("owner", "alice"))
+	assert.NoError(t, policy.SetUserMetadata("Owner", "bob"))
+	assert.Equal(t, `{"expiration":"2017-01-23T04:05:06Z","conditions":[["eq","$x-oss-meta-owner","bob"]]}`, policy.String(),
+		"Setting a key again should replace its condition")
+	assert.NoError(t, Evaluate(policy, policy.formData, 0, time.Date(2017, 1, 23, 0, 0, 0, 0, time.UTC)))
+
+	// Large values are escaped in full.
+	policy = NewPostPolicy()
+	policy.SetExpires(time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC))
+	policy.SetKeyStartsWith(strings.Repeat(`"`, MaxKeyLength))
+	assert.Contains(t, policy.String(), strings.Repeat(`\"`, MaxKeyLength))
+}
@@ -0,0 +1,62 @@
+package oss_addons
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Response headers of POST uploads that frontends commonly read.
+const (
+	HeaderETag      = "ETag"
+	HeaderRequestID = "x-oss-request-id"
+	HeaderHashCRC64 = "x-oss-hash-crc64ecma"
+	HeaderVersionID = "x-oss-version-id"
+)
+
+// ResponseHeaders - Returns the response headers a frontend needs to read
+// after a POST upload under the policy: the object's ETag, the request ID for
+// support cases, the CRC64 to check the upload's integrity, and the version
+// ID created in versioned buckets, plus the x-oss-server-side-* headers OSS
+// echoes when the policy has conditions on them. None of them is
+// CORS-safelisted, so the list is also what the bucket's CORS rule has to
+// expose (ExposeHeader).
+func (p *PostPolicy) ResponseHeaders() []string {
+	headers := []string{HeaderETag, HeaderRequestID, HeaderHashCRC64, HeaderVersionID}
+	for _, po := range p.conditions {
+		field := strings.ToLower(strings.TrimPrefix(po.condition, "$"))
+		if strings.HasPrefix(field, "x-oss-server-side-") && !containsHeader(headers, field) {
+			headers = append(headers, field)
+		}
+	}
+	return headers
+}
+
+// containsHeader - Reports whether header is one of headers.
+func containsHeader(headers []string, header string) bool {
+	for _, h := range headers {
+		if h == header {
+			return true
+		}
+	}
+	return false
+}
+
+// UploadResult - Describes the object created by a POST upload.
+type UploadResult struct {
+	ETag      string
+	RequestID string
+	HashCRC64 string
+	// VersionID is empty unless versioning is enabled on the bucket.
+	VersionID string
+}
+
+// ParseUploadResponse - Extracts the upload result from the headers of a
+// POST upload response.
+func ParseUploadResponse(h http.Header) UploadResult {
+	return UploadResult{
+		ETag:      h.Get(HeaderETag),
+		RequestID: h.Get(HeaderRequestID),
+		HashCRC64: h.Get(HeaderHashCRC64),
+		VersionID: h.Get(HeaderVersionID),
+	}
+}
@@ -0,0 +1,41 @@
+package oss_addons
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostPolicyResponseHeaders(t *testing.T) {
+	policy := NewPostPolicy()
+	policy.SetBucket("test-bucket")
+	policy.SetKeyStartsWith("user/1/")
+	assert.Equal(t, []string{"ETag", "x-oss-request-id", "x-oss-hash-crc64ecma", "x-oss-version-id"},
+		policy.ResponseHeaders())
+
+	policy.AddCondition(Eq("$x-oss-server-side-encryption", "KMS"))
+	policy.AddCondition(Eq("$X-Oss-Server-Side-Encryption-Key-Id", "9468da86-3509-4f8d-a61e-6eab1eac****"))
+	policy.AddCondition(Eq("$x-oss-server-side-encryption", "KMS"))
+	assert.Equal(t, []string{"ETag", "x-oss-request-id", "x-oss-hash-crc64ecma", "x-oss-version-id",
+		"x-oss-server-side-encryption", "x-oss-server-side-encryption-key-id"},
+		policy.ResponseHeaders())
+}
+
+func TestParseUploadResponse(t *testing.T) {
+	h := http.Header{}
+	h.Set("ETag", `"D41D8CD98F00B204E9800998ECF8427E"`)
+	h.Set("X-Oss-Request-Id", "5885815A08A7F1A7A1B8D4D1")
+	h.Set("X-Oss-Hash-Crc64ecma", "0")
+	h.Set("X-Oss-Version-Id", "CAEQNhiBgMDJgZCA0BYiIDc4MGZjZGI2OTBjOTRmNTE5NmU5NmFhZjhjYmY0****")
+
+	assert.Equal(t, UploadResult{
+		ETag:      `"D41D8CD98F00B204E9800998ECF8427E"`,
+		RequestID: "5885815A08A7F1A7A1B8D4D1",
+		HashCRC64: "0",
+		VersionID: "CAEQNhiBgMDJgZCA0BYiIDc4MGZjZGI2OTBjOTRmNTE5NmU5NmFhZjhjYmY0****",
+	}, ParseUploadResponse(h))
+
+	h.Del("X-Oss-Version-Id")
+	assert.Empty(t, ParseUploadResponse(h).VersionID)
+}
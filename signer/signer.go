@@ -3,7 +3,22 @@ package signer
 import (
 	"crypto/hmac"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// Constants used by the V4 signature scheme.
+const (
+	SignatureVersionV4 = "OSS4-HMAC-SHA256"
+	ProductV4          = "oss"
+	RequestTypeV4      = "aliyun_v4_request"
+
+	// DateFormatV4 is the format of x-oss-date.
+	DateFormatV4 = "20060102T150405Z"
+	// ScopeDateFormatV4 is the format of the date in the credential scope.
+	ScopeDateFormatV4 = "20060102"
 )
 
 // PostPresignSignatureV1 - presigned signature for PostPolicy request.
@@ -13,3 +28,30 @@ func PostPresignSignatureV1(policyBase64, secretAccessKey string) string {
 	signature := base64.StdEncoding.EncodeToString(hm.Sum(nil))
 	return signature
 }
+
+// PostPresignSignatureV4 - presigned V4 signature for PostPolicy request.
+func PostPresignSignatureV4(policyBase64, secretAccessKey string, t time.Time, region string) string {
+	signingKey := SigningKeyV4(secretAccessKey, t, region)
+	return hex.EncodeToString(sumHMAC(signingKey, []byte(policyBase64)))
+}
+
+// SigningKeyV4 - derives the V4 signing key scoped to date, region and product.
+func SigningKeyV4(secretAccessKey string, t time.Time, region string) []byte {
+	date := sumHMAC([]byte("aliyun_v4"+secretAccessKey), []byte(t.UTC().Format(ScopeDateFormatV4)))
+	dateRegion := sumHMAC(date, []byte(region))
+	dateRegionProduct := sumHMAC(dateRegion, []byte(ProductV4))
+	return sumHMAC(dateRegionProduct, []byte(RequestTypeV4))
+}
+
+// CredentialV4 - returns the V4 credential string,
+// <AccessKeyId>/<date>/<region>/oss/aliyun_v4_request.
+func CredentialV4(accessKeyID string, t time.Time, region string) string {
+	return accessKeyID + "/" + t.UTC().Format(ScopeDateFormatV4) + "/" + region + "/" + ProductV4 + "/" + RequestTypeV4
+}
+
+// sumHMAC calculates HMAC-SHA256 of data with key.
+func sumHMAC(key []byte, data []byte) []byte {
+	hm := hmac.New(sha256.New, key)
+	hm.Write(data)
+	return hm.Sum(nil)
+}
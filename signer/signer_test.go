@@ -0,0 +1,24 @@
+package signer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigningKeyV4(t *testing.T) {
+	t1 := time.Date(2017, 1, 23, 23, 59, 59, 0, time.UTC)
+	t2 := time.Date(2017, 1, 24, 7, 0, 0, 0, time.FixedZone("CST", 8*60*60))
+
+	// Keys are scoped by UTC date, region and secret.
+	assert.Equal(t, SigningKeyV4("secret", t1, "cn-hangzhou"), SigningKeyV4("secret", t2, "cn-hangzhou"))
+	assert.NotEqual(t, SigningKeyV4("secret", t1, "cn-hangzhou"), SigningKeyV4("secret", t1, "cn-beijing"))
+	assert.NotEqual(t, SigningKeyV4("secret", t1, "cn-hangzhou"), SigningKeyV4("other", t1, "cn-hangzhou"))
+	assert.NotEqual(t, SigningKeyV4("secret", t1, "cn-hangzhou"), SigningKeyV4("secret", t1.Add(time.Second), "cn-hangzhou"))
+}
+
+func TestCredentialV4(t *testing.T) {
+	ts := time.Date(2017, 1, 24, 7, 0, 0, 0, time.FixedZone("CST", 8*60*60))
+	assert.Equal(t, "ak/20170123/cn-hangzhou/oss/aliyun_v4_request", CredentialV4("ak", ts, "cn-hangzhou"))
+}
@@ -0,0 +1,183 @@
+package oss_addons
+
+import (
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// SyncPostPolicy - A PostPolicy guarded by a mutex, so a single template
+// policy can be shared and signed by concurrent requests.
+type SyncPostPolicy struct {
+	mu sync.Mutex
+	p  *PostPolicy
+}
+
+// NewSyncPostPolicy - Instantiate new concurrent-safe post policy.
+func NewSyncPostPolicy() *SyncPostPolicy {
+	return &SyncPostPolicy{p: NewPostPolicy()}
+}
+
+// SetExpires - See PostPolicy.SetExpires.
+func (s *SyncPostPolicy) SetExpires(t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.SetExpires(t)
+}
+
+// SetExpiresPreNormalized - See PostPolicy.SetExpiresPreNormalized.
+func (s *SyncPostPolicy) SetExpiresPreNormalized(preNormalized bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.p.SetExpiresPreNormalized(preNormalized)
+}
+
+// SetStrict - See PostPolicy.SetStrict.
+func (s *SyncPostPolicy) SetStrict(strict bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.SetStrict(strict)
+}
+
+// SetLimits - See PostPolicy.SetLimits.
+func (s *SyncPostPolicy) SetLimits(maxConditions, maxSize int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.SetLimits(maxConditions, maxSize)
+}
+
+// SetKey - See PostPolicy.SetKey.
+func (s *SyncPostPolicy) SetKey(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.SetKey(key)
+}
+
+// SetKeyStartsWith - See PostPolicy.SetKeyStartsWith.
+func (s *SyncPostPolicy) SetKeyStartsWith(keyStartsWith string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.SetKeyStartsWith(keyStartsWith)
+}
+
+// SetBucket - See PostPolicy.SetBucket.
+func (s *SyncPostPolicy) SetBucket(bucketName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.SetBucket(bucketName)
+}
+
+// SetContentType - See PostPolicy.SetContentType.
+func (s *SyncPostPolicy) SetContentType(contentType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.SetContentType(contentType)
+}
+
+// SetContentDisposition - See PostPolicy.SetContentDisposition.
+func (s *SyncPostPolicy) SetContentDisposition(contentDisposition string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.SetContentDisposition(contentDisposition)
+}
+
+// SetContentLengthRange - See PostPolicy.SetContentLengthRange.
+func (s *SyncPostPolicy) SetContentLengthRange(min, max int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.SetContentLengthRange(min, max)
+}
+
+// SetSuccessStatusAction - See PostPolicy.SetSuccessStatusAction.
+func (s *SyncPostPolicy) SetSuccessStatusAction(status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.SetSuccessStatusAction(status)
+}
+
+// SetSuccessActionRedirect - See PostPolicy.SetSuccessActionRedirect.
+func (s *SyncPostPolicy) SetSuccessActionRedirect(redirect string, allowedHosts ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.SetSuccessActionRedirect(redirect, allowedHosts...)
+}
+
+// SetForbidOverwrite - See PostPolicy.SetForbidOverwrite.
+func (s *SyncPostPolicy) SetForbidOverwrite() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.SetForbidOverwrite()
+}
+
+// SetSecurityToken - See PostPolicy.SetSecurityToken.
+func (s *SyncPostPolicy) SetSecurityToken(token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.SetSecurityToken(token)
+}
+
+// SetCallback - See PostPolicy.SetCallback.
+func (s *SyncPostPolicy) SetCallback(callbackJSON string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.SetCallback(callbackJSON)
+}
+
+// SetCallbackVar - See PostPolicy.SetCallbackVar.
+func (s *SyncPostPolicy) SetCallbackVar(vars map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.SetCallbackVar(vars)
+}
+
+// SetUserMetadata - See PostPolicy.SetUserMetadata.
+func (s *SyncPostPolicy) SetUserMetadata(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.SetUserMetadata(key, value)
+}
+
+// AddCondition - See PostPolicy.AddCondition.
+func (s *SyncPostPolicy) AddCondition(c Condition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.AddCondition(c)
+}
+
+// WithClientHints - See PostPolicy.WithClientHints. The returned policy is
+// not shared and therefore not synchronized.
+func (s *SyncPostPolicy) WithClientHints(size int64, contentType string) (*PostPolicy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.WithClientHints(size, contentType)
+}
+
+// PresignV1 - Signs the policy with PresignedPostPolicyV1.
+func (s *SyncPostPolicy) PresignV1(c *oss.Client) (*url.URL, map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return PresignedPostPolicyV1(c, s.p)
+}
+
+// PresignV4 - Signs the policy with PresignedPostPolicyV4.
+func (s *SyncPostPolicy) PresignV4(c *oss.Client, region string) (*url.URL, map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return PresignedPostPolicyV4(c, s.p, region)
+}
+
+// ResponseHeaders - See PostPolicy.ResponseHeaders.
+func (s *SyncPostPolicy) ResponseHeaders() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.ResponseHeaders()
+}
+
+// String - Stringer interface for printing policy in json formatted string.
+func (s *SyncPostPolicy) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.p.String()
+}
@@ -0,0 +1,32 @@
+package oss_addons
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncPostPolicyConcurrentPresign(t *testing.T) {
+	c := newTestClient()
+
+	policy := NewSyncPostPolicy()
+	policy.SetExpires(time.Date(2017, 1, 23, 4, 5, 6, 0, time.UTC))
+	policy.SetBucket("test-bucket")
+	policy.SetKey("test-object-name")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, formData, err := policy.PresignV1(c)
+			if assert.NoError(t, err) {
+				formData["x-oss-meta-owner"] = "me"
+				assert.NotEmpty(t, formData["signature"])
+			}
+		}()
+	}
+	wg.Wait()
+}
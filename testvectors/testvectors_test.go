@@ -0,0 +1,28 @@
+package testvectors
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/timonwong/ali-oss-addons/signer"
+)
+
+func TestRegressionVectors(t *testing.T) {
+	for _, v := range RegressionVectors {
+		assert.Equal(t, v.PolicyBase64, base64.StdEncoding.EncodeToString([]byte(v.PolicyJSON)), v.Name)
+		assert.Equal(t, v.SignatureV1, signer.PostPresignSignatureV1(v.PolicyBase64, v.AccessKeySecret), v.Name)
+		assert.Equal(t, v.SignatureV4, signer.PostPresignSignatureV4(v.PolicyBase64, v.AccessKeySecret, v.SigningTime, v.Region), v.Name)
+	}
+}
+
+func TestSigningVectors(t *testing.T) {
+	for _, v := range SigningVectors {
+		if v.SignatureV1 != "" {
+			assert.Equal(t, v.SignatureV1, signer.PostPresignSignatureV1(v.StringToSign, v.AccessKeySecret), v.Name)
+		}
+		if v.SignatureV4 != "" {
+			assert.Equal(t, v.SignatureV4, signer.PostPresignSignatureV4(v.StringToSign, v.AccessKeySecret, v.SigningTime, v.Region), v.Name)
+		}
+	}
+}